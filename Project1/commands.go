@@ -0,0 +1,622 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jh125486/CSCE4600/Project1/sched"
+	"github.com/olekukonko/tablewriter"
+)
+
+// command is a subcommand of the scheduler CLI.
+type command struct {
+	name  string
+	args  string // arguments, for help
+	usage string
+	run   func(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error
+}
+
+var commands = []command{
+	{
+		name:  "run",
+		args:  "<algorithm> [flags] [file]",
+		usage: "Run an algorithm over the processes in file (or piped to stdin) and output its schedule.",
+		run:   runCmd,
+	},
+	{
+		name:  "compare",
+		args:  "[flags] [file]",
+		usage: "Run several algorithms over the same processes and compare their averages.",
+		run:   compareCmd,
+	},
+	{
+		name:  "diff",
+		args:  "<algorithm|result.json> <algorithm|result.json> [flags] [file]",
+		usage: "Compare two schedules of the same processes, process by process. Algorithms are run with their default params; results are JSON, as written by run -json.",
+		run:   diffCmd,
+	},
+	{
+		name:  "check",
+		args:  "[flags] [file]",
+		usage: "Check the results a workload expects of algorithms, on its #expect lines, reporting each as PASS or FAIL with how it differs.",
+		run:   checkCmd,
+	},
+	{
+		name:  "threads",
+		args:  "[flags] [file]",
+		usage: "Schedule user threads (rows with a Process column, blocking per their IO column) mapped onto kernel threads, and compare the 1:1, N:1 and M:N models.",
+		run:   threadsCmd,
+	},
+	{
+		name:  "gang",
+		args:  "[flags] [file]",
+		usage: "Gang schedule the processes of each group (rows with a Group column) together on several CPUs, and report each group's share of them.",
+		run:   gangCmd,
+	},
+	{
+		name:  "queue",
+		args:  "[flags]",
+		usage: "Simulate an open system with Poisson arrivals and compare its steady state with M/M/1 and M/G/1 queueing theory.",
+		run:   queueCmd,
+	},
+	{
+		name:  "generate",
+		args:  "[flags]",
+		usage: "Write a random workload as CSV.",
+		run:   generateCmd,
+	},
+	{
+		name:  "validate",
+		args:  "<algorithm> [flags] [file]",
+		usage: "Run an algorithm and check its schedule against the scheduling invariants.",
+		run:   validateCmd,
+	},
+	{
+		name:  "serve",
+		args:  "[flags]",
+		usage: "Serve the web UI, and an HTTP API for running algorithms.",
+		run:   serveCmd,
+	},
+	{
+		name:  "list",
+		usage: "List the registered algorithms and their parameters.",
+		run:   listCmd,
+	},
+}
+
+func programName() string { return filepath.Base(os.Args[0]) }
+
+// isCommand reports whether name is a subcommand, rather than the data file of the original
+// scheduler flags, which may come after it.
+func isCommand(name string) bool {
+	return name == "help" || slices.ContainsFunc(commands, func(c command) bool { return c.name == name })
+}
+
+// runCommand runs the subcommand named by args[0] with the rest of args.
+func runCommand(args []string, stdin *os.File, w io.Writer) error {
+	name := args[0]
+	if name == "help" {
+		printUsage(w)
+		return nil
+	}
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+		flagSet.SetOutput(w)
+		flagSet.Usage = func() {
+			_, _ = fmt.Fprintf(w, "usage: %s %s %s\n\n%s\n", programName(), c.name, c.args, c.usage)
+			if strings.Contains(c.args, "<algorithm") {
+				_, _ = fmt.Fprintln(w, "\nalgorithms:")
+				outputAlgorithms(w)
+			}
+			_, _ = fmt.Fprintln(w, "\nflags:")
+			flagSet.PrintDefaults()
+		}
+		return c.run(flagSet, args[1:], stdin, w)
+	}
+	printUsage(w)
+
+	return fmt.Errorf("%w: unknown command %q", sched.ErrInvalidArgs, name)
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "usage: %s <command> [args]\n\ncommands:\n", programName())
+	widest := 0
+	for _, c := range commands {
+		widest = max(widest, len(strings.TrimSpace(c.name+" "+c.args)))
+	}
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(w, "  %-*s %s\n", widest, strings.TrimSpace(c.name+" "+c.args), c.usage)
+	}
+	_, _ = fmt.Fprintf(w, "\nRun '%s <command> -h' for a command's flags.\n", programName())
+	_, _ = fmt.Fprintln(w, "The original flags (-fcfs, -sjf, -sjfp, -rr) still work as aliases for run.")
+}
+
+// parseAlgorithm parses the flags of a command taking an algorithm name followed by its
+// params, common flags and data file.
+func parseAlgorithm(flagSet *flag.FlagSet, args []string, stdin *os.File, cfg *config) (sched.Algorithm, error) {
+	var alg sched.Algorithm
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var err error
+		if alg, err = sched.LookupAlgorithm(args[0]); err != nil {
+			return alg, err
+		}
+		args = args[1:]
+	}
+	values := make(map[string]*int64, len(alg.Params))
+	for _, p := range alg.Params {
+		values[p.Name] = flagSet.Int64(p.Name, p.Default, p.Usage)
+	}
+	modelFlags(flagSet, cfg)
+	dataFlags(flagSet, cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return alg, err
+	}
+	if alg.Name == "" {
+		flagSet.Usage()
+		return alg, fmt.Errorf("%w: %s requires an algorithm", sched.ErrInvalidArgs, flagSet.Name())
+	}
+	cfg.algorithm = alg.Name
+	cfg.params = make(map[string]int64, len(values))
+	for name, v := range values {
+		cfg.params[name] = *v
+	}
+	if err := alg.Validate(cfg.params); err != nil {
+		return alg, err
+	}
+
+	return alg, cfg.open(files, stdin)
+}
+
+func runCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var cfg config
+	runFlags(flagSet, &cfg)
+	if _, err := parseAlgorithm(flagSet, args, stdin, &cfg); err != nil {
+		return err
+	}
+
+	return execute(cfg, stdin, w)
+}
+
+func validateCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var cfg config
+	alg, err := parseAlgorithm(flagSet, args, stdin, &cfg)
+	if err != nil {
+		return err
+	}
+	processes, _, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+	res, err := sched.Run(alg, cfg.params, cfg.machine, processes)
+	if err != nil {
+		return err
+	}
+	if err := sched.CheckInvariants(processes, res, true); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "ok: %s schedule of %d processes satisfies every invariant\n", alg.Title, len(processes))
+
+	return err
+}
+
+func compareCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var cfg config
+	names := make([]string, len(sched.Algorithms()))
+	for i, alg := range sched.Algorithms() {
+		names[i] = alg.Name
+	}
+	algos := flagSet.String("algos", strings.Join(names, ","), "Comma separated algorithms to compare, run with their default params")
+	var expressions []string
+	flagSet.Func("policy", "A policy expression to compare too, e.g. \"priority asc, remaining asc; preemptive\" (may be repeated)", func(expr string) error {
+		expressions = append(expressions, expr)
+		return nil
+	})
+	modelFlags(flagSet, &cfg)
+	dataFlags(flagSet, &cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return err
+	}
+	var selected []sched.Algorithm
+	for _, name := range strings.Split(*algos, ",") {
+		alg, err := sched.LookupAlgorithm(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		selected = append(selected, alg)
+	}
+	for _, expr := range expressions {
+		alg, err := sched.ParseExpression(expr)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, alg)
+	}
+	if err := cfg.open(files, stdin); err != nil {
+		return err
+	}
+	processes, _, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+
+	results := make([]sched.Result, len(selected))
+	energy := false
+	for i, alg := range selected {
+		if results[i], err = sched.Run(alg, alg.Defaults(), cfg.machine, processes); err != nil {
+			return err
+		}
+		energy = energy || results[i].Energy > 0
+	}
+	header := []string{"Algorithm", "Average wait", "Average turnaround", "Throughput"}
+	if energy {
+		header = append(header, "Energy", "Energy-delay product")
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	for i, res := range results {
+		row := []string{
+			selected[i].Name,
+			res.FormatMean(res.AverageWait),
+			res.FormatMean(res.AverageTurnaround),
+			res.FormatRate(res.Throughput),
+		}
+		if energy {
+			row = append(row, fmt.Sprintf("%.2f", res.Energy), fmt.Sprintf("%.2f", res.EDP))
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	return nil
+}
+
+func diffCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var cfg config
+	modelFlags(flagSet, &cfg)
+	dataFlags(flagSet, &cfg)
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 2 {
+		flagSet.Usage()
+		return fmt.Errorf("%w: diff requires two algorithms or results", sched.ErrInvalidArgs)
+	}
+
+	// Load the results first, as only algorithms need the data.
+	var (
+		results [2]sched.Result
+		algs    [2]*sched.Algorithm
+	)
+	for i, name := range positional[:2] {
+		if alg, err := sched.LookupAlgorithm(name); err == nil {
+			algs[i] = &alg
+			continue
+		}
+		if !strings.HasSuffix(name, ".json") {
+			return fmt.Errorf("%w: %q is neither an algorithm nor a .json result", sched.ErrInvalidArgs, name)
+		}
+		if results[i], err = loadResult(name); err != nil {
+			return err
+		}
+	}
+	if algs[0] != nil || algs[1] != nil {
+		if err := cfg.open(positional[2:], stdin); err != nil {
+			return err
+		}
+		processes, _, err := loadData(cfg)
+		if err != nil {
+			return err
+		}
+		for i, alg := range algs {
+			if alg == nil {
+				continue
+			}
+			if results[i], err = sched.Run(*alg, alg.Defaults(), cfg.machine, processes); err != nil {
+				return err
+			}
+		}
+	} else if len(positional) > 2 {
+		return fmt.Errorf("%w: diffing two results takes no data file, got %q", sched.ErrInvalidArgs, positional[2:])
+	}
+
+	return sched.OutputDiff(w, results[0], results[1])
+}
+
+func checkCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) (err error) {
+	var cfg config
+	modelFlags(flagSet, &cfg)
+	dataFlags(flagSet, &cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if cfg.format != "" {
+		return fmt.Errorf("%w: check needs a CSV workload, not an imported trace", sched.ErrInvalidArgs)
+	}
+	if err := cfg.machine.Clock.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.open(files, stdin); err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := cfg.data.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("%w: error closing data file", closeErr)
+		}
+	}()
+	workload, err := sched.LoadWorkload(cfg.data, cfg.machine.Clock)
+	if err != nil {
+		return err
+	}
+
+	return sched.CheckExpectations(w, workload, cfg.machine)
+}
+
+func gangCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var (
+		cfg config
+		gm  sched.GangModel
+	)
+	flagSet.IntVar(&gm.CPUs, "cpus", 2, "CPUs the gangs are scheduled on")
+	flagSet.Int64Var(&gm.Quantum, "quantum", 1, "Time slice every gang running together gets")
+	dataFlags(flagSet, &cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return err
+	}
+	gm.Clock = cfg.machine.Clock
+	if err := gm.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.open(files, stdin); err != nil {
+		return err
+	}
+	processes, _, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+	res, err := sched.SimulateGang(gm, processes, fmt.Sprintf("Gang scheduling on %d CPUs", gm.CPUs))
+	if err != nil {
+		return err
+	}
+	sched.OutputGangResult(w, res)
+
+	return nil
+}
+
+func threadsCmd(flagSet *flag.FlagSet, args []string, stdin *os.File, w io.Writer) error {
+	var cfg config
+	tm := sched.ThreadModel{}
+	flagSet.StringVar(&tm.Name, "model", "M:N", "How user threads map onto kernel threads: "+strings.Join(sched.ThreadModels, ", "))
+	flagSet.IntVar(&tm.KernelThreads, "kernel-threads", 2, "Kernel threads per process under M:N")
+	kernelName := flagSet.String("kernel", "rr", "Algorithm or policy expression scheduling kernel threads on the CPU, with its default params")
+	userName := flagSet.String("user", "fcfs", "Algorithm or policy expression ordering each process's threads waiting for a kernel thread")
+	dataFlags(flagSet, &cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if err := tm.Validate(); err != nil {
+		return err
+	}
+	kernel, err := sched.LookupAlgorithm(*kernelName)
+	if err != nil {
+		return err
+	}
+	user, err := sched.LookupAlgorithm(*userName)
+	if err != nil {
+		return err
+	}
+	if err := cfg.open(files, stdin); err != nil {
+		return err
+	}
+	threads, _, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+
+	m := sched.Machine{Clock: cfg.machine.Clock}
+	simulate := func(tm sched.ThreadModel) (sched.ThreadResult, error) {
+		title := fmt.Sprintf("%s threads: %s kernel, %s user", tm, kernel.Title, user.Title)
+		return sched.SimulateThreads(kernel.Policy(kernel.Defaults(), m), user.Policy(user.Defaults(), m), tm, threads, title)
+	}
+	res, err := simulate(tm)
+	if err != nil {
+		return err
+	}
+	sched.OutputThreadResult(w, res)
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Thread models")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Model", "Average process turnaround", "Average thread turnaround", "Average wait"})
+	for _, name := range sched.ThreadModels {
+		res, err := simulate(sched.ThreadModel{Name: name, KernelThreads: tm.KernelThreads})
+		if err != nil {
+			return err
+		}
+		table.Append([]string{
+			res.Model,
+			res.FormatMean(res.AverageProcessTurnaround),
+			res.FormatMean(res.AverageTurnaround),
+			res.FormatMean(res.AverageWait),
+		})
+	}
+	table.Render()
+
+	return nil
+}
+
+// loadResult reads a schedule written as JSON.
+func loadResult(name string) (sched.Result, error) {
+	var res sched.Result
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return res, fmt.Errorf("%w: error opening result file", err)
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return res, fmt.Errorf("%w: reading %s: %w", sched.ErrInvalidArgs, name, err)
+	}
+
+	return res, nil
+}
+
+func queueCmd(flagSet *flag.FlagSet, args []string, _ *os.File, w io.Writer) error {
+	shape := sched.OpenShape{}
+	flagSet.Float64Var(&shape.Rate, "rate", 0.008, "Arrivals per tick")
+	flagSet.Float64Var(&shape.Service, "service", 100, "Mean burst, in ticks")
+	flagSet.StringVar(&shape.Dist, "dist", "exp", "Distribution of bursts: "+strings.Join(sched.ServiceDists, ", "))
+	flagSet.Int64Var(&shape.Horizon, "horizon", 1_000_000, "Time at which arrivals stop")
+	warmup := flagSet.Int64("warmup", 100_000, "Time before which the queue isn't measured, while it fills up")
+	algos := flagSet.String("algos", "fcfs", "Comma separated algorithms to simulate, run with their default params")
+	seed := flagSet.Uint64("seed", 1, "Random seed, the same seed always generates the same arrivals")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 0 {
+		return fmt.Errorf("%w: queue takes no arguments", sched.ErrInvalidArgs)
+	}
+	if err := shape.Validate(); err != nil {
+		return err
+	}
+	if *warmup < 0 || *warmup >= shape.Horizon {
+		return fmt.Errorf("%w: -warmup must be within the horizon", sched.ErrInvalidArgs)
+	}
+	var selected []sched.Algorithm
+	for _, name := range strings.Split(*algos, ",") {
+		alg, err := sched.LookupAlgorithm(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		selected = append(selected, alg)
+	}
+
+	processes := sched.GenerateOpen(rand.New(rand.NewPCG(*seed, 0)), shape)
+	mean, second := sched.ServiceMoments(processes)
+	sched.OutputTitle(w, fmt.Sprintf("Open system, %d arrivals", len(processes)))
+	_, _ = fmt.Fprintf(w, "Arrival rate λ: %g per tick\n", shape.Rate)
+	_, _ = fmt.Fprintf(w, "Service (%s): E[S] = %.2f, E[S²] = %.2f\n", shape.Dist, mean, second)
+	_, _ = fmt.Fprintf(w, "Utilization ρ = λE[S]: %.3f\n", shape.Rate*mean)
+	_, _ = fmt.Fprintf(w, "Measured over [%d, %d)\n\n", *warmup, shape.Horizon)
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Model", "Processes", "Utilization", "Mean in system", "Mean queue length", "Mean response", "Mean wait"})
+	row := func(name string, st sched.SteadyState) {
+		processes := "" // predictions aren't of any particular processes
+		if st.Processes > 0 {
+			processes = fmt.Sprint(st.Processes)
+		}
+		table.Append([]string{
+			name,
+			processes,
+			fmt.Sprintf("%.3f", st.Utilization),
+			fmt.Sprintf("%.2f", st.MeanInSystem),
+			fmt.Sprintf("%.2f", st.MeanQueue),
+			fmt.Sprintf("%.2f", st.MeanResponse),
+			fmt.Sprintf("%.2f", st.MeanWait),
+		})
+	}
+	var fcfs *sched.SteadyState
+	for _, alg := range selected {
+		res, err := sched.Run(alg, alg.Defaults(), sched.Machine{}, processes)
+		if err != nil {
+			return err
+		}
+		st := sched.MeasureSteadyState(res, *warmup, shape.Horizon)
+		if alg.Name == "fcfs" {
+			fcfs = &st
+		}
+		row(alg.Name, st)
+	}
+	var models []string
+	predictions := make(map[string]sched.SteadyState)
+	if st, ok := sched.MM1(shape.Rate, shape.Service); ok {
+		models, predictions["M/M/1"] = append(models, "M/M/1"), st
+	}
+	if st, ok := sched.MG1(shape.Rate, mean, second); ok {
+		models, predictions["M/G/1"] = append(models, "M/G/1"), st
+	}
+	for _, model := range models {
+		row(model, predictions[model])
+	}
+	table.Render()
+
+	switch {
+	case len(models) == 0:
+		_, _ = fmt.Fprintln(w, "\nNo steady state to predict: the queue is unstable (ρ ≥ 1) and grows without bound.")
+	case fcfs != nil:
+		_, _ = fmt.Fprintln(w, "\nFCFS mean response vs theory:")
+		for _, model := range models {
+			_, _ = fmt.Fprintf(w, "  %s: %+.1f%%\n", model, 100*(fcfs.MeanResponse/predictions[model].MeanResponse-1))
+		}
+	}
+
+	return nil
+}
+
+func generateCmd(flagSet *flag.FlagSet, args []string, _ *os.File, w io.Writer) error {
+	var shape sched.WorkloadShape
+	flagSet.IntVar(&shape.Count, "n", 10, "Number of processes")
+	flagSet.Int64Var(&shape.MaxArrival, "max-arrival", 20, "Latest arrival time")
+	flagSet.Int64Var(&shape.MaxBurst, "max-burst", 10, "Longest burst duration")
+	flagSet.Int64Var(&shape.MaxPriority, "max-priority", 50, "Lowest priority (highest number)")
+	flagSet.Int64Var(&shape.MaxMemory, "max-memory", 0, "Largest memory requirement (zero leaves memory out)")
+	flagSet.IntVar(&shape.Groups, "groups", 0, "Number of groups to spread processes over (zero leaves groups out)")
+	seed := flagSet.Uint64("seed", 1, "Random seed, the same seed always generates the same workload")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 0 {
+		return fmt.Errorf("%w: generate takes no arguments", sched.ErrInvalidArgs)
+	}
+	if err := shape.Validate(); err != nil {
+		return err
+	}
+
+	return sched.WriteProcesses(w, sched.GenerateWorkload(rand.New(rand.NewPCG(*seed, 0)), shape))
+}
+
+func listCmd(flagSet *flag.FlagSet, args []string, _ *os.File, w io.Writer) error {
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 0 {
+		return fmt.Errorf("%w: list takes no arguments", sched.ErrInvalidArgs)
+	}
+	outputAlgorithms(w)
+
+	return nil
+}
+
+func outputAlgorithms(w io.Writer) {
+	widest := 0
+	for _, alg := range sched.Algorithms() {
+		widest = max(widest, len(alg.Name))
+	}
+	for _, alg := range sched.Algorithms() {
+		_, _ = fmt.Fprintf(w, "  %-*s %s\n", widest, alg.Name, alg.Usage)
+		for _, p := range alg.Params {
+			bounds := fmt.Sprintf("minimum %d", p.Min)
+			if p.Max > 0 {
+				bounds += fmt.Sprintf(", maximum %d", p.Max)
+			}
+			_, _ = fmt.Fprintf(w, "  %-*s   -%s int: %s (default %d, %s)\n", widest, "", p.Name, p.Usage, p.Default, bounds)
+		}
+	}
+	_, _ = fmt.Fprintln(w, "  or a policy expression: the ready queue's order as comma separated <key> [asc|desc] terms")
+	_, _ = fmt.Fprintln(w, "  (keys arrival, burst, remaining, cpu and priority), then optionally ; preemptive and ; quantum <n>,")
+	_, _ = fmt.Fprintln(w, "  e.g. \"priority asc, remaining asc, arrival asc; preemptive\"")
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/jh125486/CSCE4600/Project1/sched"
+)
+
+func Test_runCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "list shows params",
+			args: []string{"list"},
+			want: []string{"fcfs", "sjfp", "-quantum int: time slice length (default 1, minimum 1)"},
+		},
+		{
+			name: "list aligns long names",
+			args: []string{"list"},
+			want: []string{"  fcfs         First-come", "\n  sjf-predict  Shortest", "\n  sjfp-inherit Priority", "\n                 -quantum int"},
+		},
+		{
+			name: "help aligns long commands",
+			args: []string{"help"},
+			want: []string{"\n  list                                                                List the", "\n  diff <algorithm|result.json> <algorithm|result.json> [flags] [file] Compare"},
+		},
+		{
+			name: "run with params",
+			args: []string{"run", "rr", "example_processes.csv", "-quantum", "3"},
+			want: []string{"Round-robin", "|  1  |  2  |  3  |  4  |  1  |  5  |"},
+		},
+		{
+			name: "compare",
+			args: []string{"compare", "-algos", "fcfs,sjf", "example_processes.csv"},
+			want: []string{"| fcfs      |         7.60 |", "| sjf       |         2.20 |"},
+		},
+		{
+			name: "validate",
+			args: []string{"validate", "sjfp", "example_processes.csv"},
+			want: []string{"ok: Priority schedule of 5 processes satisfies every invariant"},
+		},
+		{
+			name: "diff",
+			args: []string{"diff", "fcfs", "rr", "example_processes.csv"},
+			want: []string{
+				"First-come, first-serve | 1  | 1  |",
+				"Round-robin             | 1  | 2  |",
+				"|  2 |  9 → 0 |     -9 |",
+				"4 of 5 processes finish sooner under Round-robin, 1 later, 0 the same",
+			},
+		},
+		{
+			name:    "diff needs two schedules",
+			args:    []string{"diff", "fcfs", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "generate is deterministic",
+			args: []string{"generate", "-n", "2", "-seed", "4600"},
+			want: []string{"ProcessID,Burst Duration,Arrival Time,Priority\nP0,", "\nP1,"},
+		},
+		{
+			name:    "run needs an algorithm",
+			args:    []string{"run", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "unknown algorithm",
+			args:    []string{"run", "lottery", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "memory limit",
+			args: []string{"run", "fcfs", "-memory", "64", "example_processes.csv"},
+			want: []string{"| ID | PRIORITY | BURST | ARRIVAL | MEMORY | ADMISSION |", "Swaps: 0 (memory limit 64)"},
+		},
+		{
+			name: "energy is reported under a power model",
+			args: []string{"compare", "-algos", "fcfs,energy", "-power", "default", "example_processes.csv"},
+			want: []string{"ENERGY-DELAY PRODUCT", "| energy    |         2.20 |"},
+		},
+		{
+			name:    "swapping needs a memory limit",
+			args:    []string{"run", "sjf", "-swap", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "params are validated",
+			args:    []string{"run", "rr", "-quantum", "0", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "params are bounded",
+			args:    []string{"run", "sjf-predict", "-alpha", "101", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "predicted SJF",
+			args: []string{"run", "sjf-predict", "example_processes.csv"},
+			want: []string{"| PREDICTED |", "Penalty vs oracle SJF: +5.40 average wait (oracle 2.20)"},
+		},
+		{
+			name: "queue",
+			args: []string{"queue", "-rate", "0.05", "-service", "10", "-dist", "const", "-horizon", "20000", "-warmup", "2000"},
+			want: []string{"| fcfs  |", "| M/M/1 |", "| M/G/1 |", "FCFS mean response vs theory:"},
+		},
+		{
+			name:    "queue warms up within the horizon",
+			args:    []string{"queue", "-horizon", "10", "-warmup", "10"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "decimal times",
+			args: []string{"run", "rr", "-decimals", "2", "-time-unit", "ms", "example_processes.csv"},
+			want: []string{"|  1   |  2   |  1   |", "0.00   1.00   2.00", "Average wait: 4.2000 ms", "Throughput: 0.26 per ms"},
+		},
+		{
+			name: "check",
+			args: []string{"check", "sched/testdata/workloads/fixture.csv"},
+			want: []string{"PASS fcfs (line 6)", "PASS rr quantum=4 (line 10)", `PASS "priority asc; preemptive" (line 11)`, "6 of 6 expectations passed"},
+		},
+		{
+			name:    "check needs expectations",
+			args:    []string{"check", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "policy expression",
+			args: []string{"run", "priority, remaining; preemptive", "example_processes.csv"},
+			want: []string{"Policy: priority asc, remaining asc; preemptive", "|  1  |  2  |  1  |  5  |  1  |  3  |  4  |"},
+		},
+		{
+			name: "policy expression quantum is a param",
+			args: []string{"run", "fifo; quantum 1", "-quantum", "3", "example_processes.csv"},
+			want: []string{"|  1  |  2  |  3  |  4  |  1  |  5  |"},
+		},
+		{
+			name: "compare policy expressions",
+			args: []string{"compare", "-algos", "sjf", "-policy", "remaining; preemptive", "example_processes.csv"},
+			want: []string{"| sjf                       |         2.20 |", "| remaining asc; preemptive |         2.20 |"},
+		},
+		{
+			name:    "bad policy expression",
+			args:    []string{"run", "priority sideways", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "gang",
+			args: []string{"gang", "-cpus", "3", "example_gangs.csv"},
+			want: []string{"Gantt schedule, by CPU\nCPU 0 | A1 | A1 | B1 | A2 |", "CPU utilization: 91.7%", "| A     |         2 |   5 |             5 | 45.5%           |"},
+		},
+		{
+			name:    "gang larger than the machine",
+			args:    []string{"gang", "-cpus", "2", "example_gangs.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "threads",
+			args: []string{"threads", "-model", "N:1", "example_threads.csv"},
+			want: []string{"Processes, N:1", "| A       |       3 |              1 |       0 |   15 |         15 |", "| M:N (2 kernel threads) |"},
+		},
+		{
+			name:    "threads need a known model",
+			args:    []string{"threads", "-model", "1:N", "example_threads.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name: "stream",
+			args: []string{"run", "sjf", "-stream", "example_processes.csv"},
+			want: []string{"Start,Stop,ID\n", "ID,Priority,Burst,Arrival,Wait,Turnaround,Exit\n", "Average wait: 2.20"},
+		},
+		{
+			name:    "stream doesn't chart",
+			args:    []string{"run", "sjf", "-stream", "-json", "example_processes.csv"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"schedule"},
+			wantErr: sched.ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var w bytes.Buffer
+			err := runCommand(tt.args, terminal(t), &w)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("runCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(w.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, w.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_diffCmd_results(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	var results [2]string
+	for i, alg := range []string{"fcfs", "sjf"} {
+		var w bytes.Buffer
+		if err := runCommand([]string{"run", alg, "-json", "example_processes.csv"}, terminal(t), &w); err != nil {
+			t.Fatal(err)
+		}
+		results[i] = path.Join(dir, alg+".json")
+		if err := os.WriteFile(results[i], w.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var w bytes.Buffer
+	if err := runCommand([]string{"diff", results[0], results[1]}, terminal(t), &w); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Average wait: 7.60 → 2.20 (-5.40)"; !strings.Contains(w.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, w.String())
+	}
+}
+
+func Test_validateCmd_negativeBurst(t *testing.T) {
+	t.Parallel()
+	// a negative burst could never be received in full, so it's rejected as the data loads.
+	data := path.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(data, []byte("ProcessID,Burst Duration,Arrival Time,Priority\nA,-1,0,1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	if err := runCommand([]string{"validate", "fcfs", data}, terminal(t), &w); !errors.Is(err, sched.ErrInvalidArgs) {
+		t.Errorf("runCommand() error = %v, want %v", err, sched.ErrInvalidArgs)
+	}
+}
+
+func Test_checkCmd_failure(t *testing.T) {
+	t.Parallel()
+	data := path.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(data, []byte(`ProcessID,Burst Duration,Arrival Time,Priority
+A,3,0,1
+B,2,1,1
+#expect sjf wait=1.50 gantt=A:0-1,B:1-3,A:3-5
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	if err := runCommand([]string{"check", data}, terminal(t), &w); !errors.Is(err, sched.ErrExpectation) {
+		t.Errorf("runCommand() error = %v, want %v", err, sched.ErrExpectation)
+	}
+	for _, want := range []string{"FAIL sjf (line 4)", "average wait is 1.00, want 1.50", "want | A | B | A |", "got  | A | A | B |"} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, w.String())
+		}
+	}
+}
@@ -1,230 +1,284 @@
 package main
 
 import (
-	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"strconv"
-	"strings"
+	"time"
 
-	"github.com/olekukonko/tablewriter"
+	"github.com/jh125486/CSCE4600/Project1/sched"
 )
 
 func main() {
+	// subcommands.
+	if len(os.Args) > 1 && isCommand(os.Args[1]) {
+		if err := runCommand(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	// parse args.
 	flagSet := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	scheduler, data, err := parseCLI(flagSet, os.Args[1:])
+	flagSet.Usage = func() {
+		printUsage(flagSet.Output())
+		_, _ = fmt.Fprintln(flagSet.Output(), "\nflags (aliases for run):")
+		flagSet.PrintDefaults()
+	}
+	cfg, err := parseCLI(flagSet, os.Args[1:], os.Stdin)
 	if err != nil {
 		_, _ = fmt.Fprintln(os.Stdout, err)
 		flagSet.PrintDefaults()
 		os.Exit(1)
 	}
-
-	// Load and parse processes.
-	processes, err := loadProcesses(data)
-	if err != nil {
+	if err := execute(cfg, os.Stdin, os.Stdout); err != nil {
 		log.Fatal(err)
 	}
-
-	// Run the given scheduler.
-	switch scheduler {
-	case fcfs:
-		FCFSSchedule(os.Stdout, "First-come, first-serve", processes)
-	case sjf:
-		SJFSchedule(os.Stdout, "Shortest-job-first", processes)
-	case sjfp:
-		SJFPrioritySchedule(os.Stdout, "Priority", processes)
-	case rr:
-		RRSchedule(os.Stdout, "Round-robin", processes)
-	}
 }
 
-//go:generate stringer -type=Scheduler
-type Scheduler uint
-
-const (
-	fcfs Scheduler = iota + 1
-	sjf
-	sjfp
-	rr
-)
-
-func parseCLI(flagSet *flag.FlagSet, args []string) (cmd Scheduler, data io.Reader, err error) {
-	fcfsFlag := flagSet.Bool(fcfs.String(), false, "First-come, first-serve scheduling")
-	sjfFlag := flagSet.Bool(sjf.String(), false, "Shortest-job-first scheduling")
-	sjfpFlag := flagSet.Bool(sjfp.String(), false, "Shortest-job-first with priority scheduling")
-	rrFlag := flagSet.Bool(rr.String(), false, "Round-robin scheduling")
-	if err := flagSet.Parse(args); err != nil {
-		return 0, nil, err
-	}
-	// validate only one flag is set
-	var count int
-	if *fcfsFlag {
-		count++
-		cmd = fcfs
-	}
-	if *sjfFlag {
-		count++
-		cmd = sjf
-	}
-	if *sjfpFlag {
-		count++
-		cmd = sjfp
+// execute loads the configured data and runs (or steps through) the configured algorithm.
+func execute(cfg config, stdin io.Reader, w io.Writer) (err error) {
+	// Load and parse processes, either as CSV or from a recorded trace, which also closes the
+	// data whatever happens next.
+	processes, recorded, err := loadData(cfg)
+	if err != nil {
+		return err
 	}
-	if *rrFlag {
-		count++
-		cmd = rr
+	alg, err := sched.LookupAlgorithm(cfg.algorithm)
+	if err != nil {
+		return err
 	}
-	switch count {
-	case 0:
-		return 0, nil, fmt.Errorf("one scheduler flag must be set")
-	case 1:
-		// validate that data file is piped in.
-		if data, err := readData(os.Args[:2]); err != nil {
-			return 0, nil, err
-		} else {
-			return cmd, data, nil
+
+	// Open the decision trace.
+	var observe func(sched.Event)
+	if cfg.trace != "" {
+		f, err := os.Create(cfg.trace)
+		if err != nil {
+			return fmt.Errorf("%w: error creating trace file", err)
 		}
-	default:
-		return 0, nil, fmt.Errorf("only one scheduler flag must be set")
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("%w: error closing trace file", closeErr)
+			}
+		}()
+		observe = sched.TraceEvents(f)
 	}
-}
 
-func readData(args []string) (io.Reader, error) {
-	fi, _ := os.Stdin.Stat()
-	if (fi.Mode() & os.ModeCharDevice) == 0 {
-		return os.Stdin, nil
-	} else if len(args) == 1 {
-		return nil, fmt.Errorf("scheduler data must be passed in or file given as last argument")
+	p := alg.Policy(cfg.params, cfg.machine)
+	if err := p.Validate(processes); err != nil {
+		return err
 	}
-	r, err := os.Open(os.Args[2])
-	if err != nil {
-		return nil, fmt.Errorf("%w: error opening data file", err)
+	// Stream the schedule of a large workload.
+	if cfg.stream {
+		if cfg.step || cfg.json || cfg.live > 0 || cfg.real > 0 {
+			return fmt.Errorf("%w: -stream can't be combined with -step, -json, -live or -real", sched.ErrInvalidArgs)
+		}
+		return sched.StreamSchedule(w, alg.Title, p, processes, observe)
 	}
 
-	return r, nil
-}
+	// Step through the given scheduler.
+	if cfg.step {
+		sched.StepSchedule(stdin, w, alg.Title, p, processes, observe)
+		return nil
+	}
 
-func openProcessingFile(args ...string) (*os.File, func(), error) {
-	if len(args) != 2 {
-		return nil, nil, fmt.Errorf("%w: must give a scheduling file to process", ErrInvalidArgs)
+	// Run the given scheduler.
+	sim := sched.Simulate(p, processes, observe)
+	if cfg.json {
+		return json.NewEncoder(w).Encode(sim.Result(alg.Title))
 	}
-	// Read in CSV process CSV file
-	f, err := os.Open(args[1])
-	if err != nil {
-		return nil, nil, fmt.Errorf("%v: error opening scheduling file", err)
+	sched.OutputResult(w, sim.Result(alg.Title))
+
+	// Run it again on goroutines.
+	if cfg.live > 0 {
+		_, _ = fmt.Fprintln(w)
+		sched.OutputLive(w, fmt.Sprintf("Live run (1 tick = %v)", cfg.live), sched.RunLive(sim, cfg.live))
 	}
-	closeFn := func() {
-		if err := f.Close(); err != nil {
-			log.Fatalf("%v: error closing scheduling file", err)
+
+	// Run it again with the real commands.
+	if cfg.real > 0 {
+		res, err := sched.RunReal(sim, cfg.real)
+		if err != nil {
+			return err
 		}
+		_, _ = fmt.Fprintln(w)
+		sched.OutputLive(w, fmt.Sprintf("Real processes (1 tick = %v)", cfg.real), res)
 	}
 
-	return f, closeFn, nil
+	// Compare with what the kernel actually did.
+	if recorded != nil {
+		_, _ = fmt.Fprintln(w)
+		res := sched.Summarize("Recorded ("+cfg.format+")", nil, recorded.Recorded)
+		res.Timescale = cfg.machine.Clock
+		sched.OutputResult(w, res)
+	}
+
+	return nil
 }
 
-//region Output helpers
+type config struct {
+	algorithm string
+	params    map[string]int64
+	data      io.ReadCloser
+	step      bool
+	json      bool
+	stream    bool
+	machine   sched.Machine
+	live      time.Duration
+	real      time.Duration
+	trace     string
+	format    string
+	unit      time.Duration
+}
 
-func outputTitle(w io.Writer, title string) {
-	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
-	_, _ = fmt.Fprintln(w, strings.Repeat(" ", len(title)/2), title)
-	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
+// dataFlags adds the flags describing the data file to flagSet.
+func dataFlags(flagSet *flag.FlagSet, cfg *config) {
+	flagSet.StringVar(&cfg.format, "import", "", "Read a recorded trace instead of CSV: perf (perf sched timehist) or procstat (/proc/[pid]/stat samples)")
+	flagSet.DurationVar(&cfg.unit, "unit", time.Millisecond, "Length of a tick when importing a recorded trace")
+	flagSet.IntVar(&cfg.machine.Clock.Decimals, "decimals", 0, "Decimal places of the times in the data and output, a tick being the last place (params such as -quantum stay in whole units)")
+	flagSet.StringVar(&cfg.machine.Clock.Unit, "time-unit", "", "Name of the unit times are written in, e.g. ms, shown in output")
 }
 
-func outputGantt(w io.Writer, gantt []TimeSlice) {
-	_, _ = fmt.Fprintln(w, "Gantt schedule")
+// modelFlags adds the flags describing the simulated machine to flagSet.
+func modelFlags(flagSet *flag.FlagSet, cfg *config) {
+	flagSet.Int64Var(&cfg.machine.Memory, "memory", 0, "Memory available to processes, which wait to be admitted until theirs fits (zero is unlimited)")
+	flagSet.BoolVar(&cfg.machine.Swap, "swap", false, "Swap out ready processes to admit a process that would be dispatched before them (needs -memory)")
+	flagSet.Func("power", "Report energy use under a power model: comma separated <speed>:<power> levels and idle:<power>, or \"default\" for "+sched.DefaultPowerModel, func(spec string) (err error) {
+		cfg.machine.Power, err = sched.ParsePowerModel(spec)
+		return err
+	})
+}
 
-	buffer := 2
-	widest := 0
-	for _, slice := range gantt {
-		if len(slice.PID) > widest {
-			widest = len(slice.PID)
-		}
-	}
+// runFlags adds the flags controlling a run to flagSet.
+func runFlags(flagSet *flag.FlagSet, cfg *config) {
+	flagSet.BoolVar(&cfg.step, "step", false, "Step through the schedule interactively (data must be given as a file)")
+	flagSet.BoolVar(&cfg.json, "json", false, "Output the result as JSON, e.g. for diff (not with -live or -real)")
+	flagSet.BoolVar(&cfg.stream, "stream", false, "Write the schedule as CSV while it is made, instead of charting it, for large workloads")
+	flagSet.StringVar(&cfg.trace, "trace", "", "Write every scheduling decision to the given file as JSON lines")
+	flagSet.DurationVar(&cfg.live, "live", 0, "Also run the schedule on goroutines doing busy work, with a tick lasting this long, and compare")
+	flagSet.DurationVar(&cfg.real, "real", 0, "Also launch each process's command and schedule them with SIGSTOP/SIGCONT (Linux only), with a tick lasting this long, and compare")
+}
 
-	// fill in empty time slices in the gantt.
-	for i := 1; i < len(gantt); i++ {
-		if gantt[i].Start > gantt[i-1].Stop {
-			gantt = append(gantt[:i], append([]TimeSlice{{
-				PID: "-", Start: gantt[i-1].Stop, Stop: gantt[i].Start,
-			}}, gantt[i:]...)...)
-			i++
+// parseInterspersed parses flags that may come before, between or after the positional
+// arguments, which are returned.
+func parseInterspersed(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		if flagSet.NArg() == 0 {
+			return positional, nil
 		}
+		positional = append(positional, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
 	}
+}
 
-	_, _ = fmt.Fprintf(w, "|")
-	last := gantt[0].Start
-	for _, slice := range gantt {
-		_, _ = fmt.Fprint(w, strings.Repeat(" ", buffer))
-		if slice.Start > last {
-			_, _ = fmt.Fprint(w, strings.Repeat(" ", widest))
-		} else {
-			_, _ = fmt.Fprint(w, slice.PID)
-		}
-		_, _ = fmt.Fprint(w, strings.Repeat(" ", buffer)+"|")
-		last = slice.Stop
-	}
-	_, _ = fmt.Fprintf(w, "\n")
-	width := buffer + widest + buffer + 1
-	for i := range gantt {
-		t := fmt.Sprint(gantt[i].Start)
-		_, _ = fmt.Fprint(w, t)
-		_, _ = fmt.Fprint(w, strings.Repeat(" ", width-len(t)))
-		if i == len(gantt)-1 {
-			_, _ = fmt.Fprint(w, gantt[i].Stop)
+// parseCLI parses the original boolean scheduler flags, which are aliases for the run
+// command, and opens the data to schedule: either the one positional argument (a file name,
+// or - for stdin), or stdin when it is piped in.
+// Flags may come before or after the data file.
+func parseCLI(flagSet *flag.FlagSet, args []string, stdin *os.File) (cfg config, err error) {
+	selected := make([]*bool, len(sched.Algorithms()))
+	for i, alg := range sched.Algorithms() {
+		selected[i] = flagSet.Bool(alg.Name, false, alg.Usage)
+	}
+	runFlags(flagSet, &cfg)
+	modelFlags(flagSet, &cfg)
+	dataFlags(flagSet, &cfg)
+	files, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return cfg, err
+	}
+	// validate only one flag is set
+	var count int
+	for i, alg := range sched.Algorithms() {
+		if *selected[i] {
+			count++
+			cfg.algorithm = alg.Name
+			cfg.params = alg.Defaults()
 		}
 	}
+	switch count {
+	case 0:
+		return cfg, fmt.Errorf("%w: one scheduler flag must be set", sched.ErrInvalidArgs)
+	case 1:
+	default:
+		return cfg, fmt.Errorf("%w: only one scheduler flag must be set", sched.ErrInvalidArgs)
+	}
+	if err := cfg.open(files, stdin); err != nil {
+		return cfg, err
+	}
 
-	_, _ = fmt.Fprintf(w, "\n\n")
+	return cfg, nil
 }
 
-func outputSchedule(w io.Writer, rows [][]string, wait, turnaround, throughput float64) {
-	_, _ = fmt.Fprintln(w, "Schedule table")
-	table := tablewriter.NewWriter(w)
-	table.SetHeader([]string{"ID", "Priority", "Burst", "Arrival", "Wait", "Turnaround", "Exit"})
-	table.AppendBulk(rows)
-	table.Render()
-	_, _ = fmt.Fprintln(w)
-	_, _ = fmt.Fprintf(w, "Average wait: %.2f\n", wait)
-	_, _ = fmt.Fprintf(w, "Average turnaround: %.2f\n", turnaround)
-	_, _ = fmt.Fprintf(w, "Throughput: %.2f\n", throughput)
+// open opens the data file named in files.
+func (cfg *config) open(files []string, stdin *os.File) (err error) {
+	// the step viewer reads its commands from stdin, so data must come from a file.
+	if cfg.step && (len(files) == 0 || files[0] == "-") {
+		return fmt.Errorf("%w: -step requires a data file", sched.ErrInvalidArgs)
+	}
+	if cfg.json && (cfg.step || cfg.live > 0 || cfg.real > 0) {
+		return fmt.Errorf("%w: -json can't be combined with -step, -live or -real", sched.ErrInvalidArgs)
+	}
+	cfg.data, err = readData(files, stdin)
+	return err
 }
 
-//endregion
-
-//region Loading processes.
-
-var ErrInvalidArgs = errors.New("invalid args")
-
-func loadProcesses(r io.Reader) ([]Process, error) {
-	rows, err := csv.NewReader(r).ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("%w: reading CSV", err)
-	}
-	rows = rows[1:] // skip header row
-	processes := make([]Process, len(rows))
-	for i := range rows {
-		processes[i].ProcessID = rows[i][0]
-		processes[i].BurstDuration = mustStrToInt(rows[i][1])
-		processes[i].ArrivalTime = mustStrToInt(rows[i][2])
-		if len(rows[i]) == 4 {
-			processes[i].Priority = mustStrToInt(rows[i][3])
+// readData opens the data file named in args, where - is stdin.
+// Without args, stdin is only read if data is being piped to it.
+func readData(args []string, stdin *os.File) (io.ReadCloser, error) {
+	switch {
+	case len(args) > 1:
+		return nil, fmt.Errorf("%w: expected a single data file, got %q", sched.ErrInvalidArgs, args)
+	case len(args) == 1 && args[0] == "-":
+		return io.NopCloser(stdin), nil
+	case len(args) == 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: error opening data file", err)
 		}
+		return f, nil
+	}
+	fi, err := stdin.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("%w: checking stdin", err)
+	}
+	if fi.Mode()&os.ModeCharDevice == 0 {
+		return io.NopCloser(stdin), nil
 	}
 
-	return processes, nil
+	return nil, fmt.Errorf("%w: scheduler data must be piped in, or a file (or - for stdin) given as an argument", sched.ErrInvalidArgs)
 }
 
-func mustStrToInt(s string) int64 {
-	i, err := strconv.ParseInt(s, 10, 64)
+// loadData reads and closes the configured data, returning the recorded trace too when one
+// is being imported.
+func loadData(cfg config) (processes []sched.Process, recorded *sched.Trace, err error) {
+	defer func() {
+		if closeErr := cfg.data.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("%w: error closing data file", closeErr)
+		}
+	}()
+	if err := cfg.machine.Clock.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if cfg.format == "" {
+		processes, err = sched.LoadProcessesScaled(cfg.data, cfg.machine.Clock)
+		return processes, nil, err
+	}
+	trace, err := sched.ImportTrace(cfg.data, cfg.format, cfg.unit)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, nil, err
 	}
-	return i
-}
 
-//endregion
+	return trace.Processes, &trace, nil
+}
@@ -1,266 +1,257 @@
 package main
 
 import (
-	"bytes"
 	"errors"
+	"flag"
 	"io"
 	"os"
+	"os/exec"
 	"path"
 	"strings"
 	"testing"
-	"testing/iotest"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jh125486/CSCE4600/Project1/sched"
 )
 
-func TestFCFSSchedule(t *testing.T) {
-	t.Parallel()
-	type args struct {
-		processes []Process
-		title     string
+// Test_main runs main in a subprocess, the test binary running itself with the args in
+// $SCHED_MAIN_ARGS, to check subcommands are told apart from a data file given before the
+// original scheduler flags.
+func Test_main(t *testing.T) {
+	if args, ok := os.LookupEnv("SCHED_MAIN_ARGS"); ok {
+		os.Args = append([]string{"sched"}, strings.Fields(args)...)
+		main()
+		return
 	}
+	t.Parallel()
 	tests := []struct {
 		name    string
-		args    args
-		wantOut string
+		args    string
+		want    string
+		wantErr bool
 	}{
 		{
-			name: "default",
-			args: args{
-				processes: []Process{
-					{
-						ProcessID:     "P0",
-						ArrivalTime:   0,
-						BurstDuration: 5,
-						Priority:      2,
-					},
-					{
-						ProcessID:     "P1",
-						ArrivalTime:   3,
-						BurstDuration: 9,
-						Priority:      1,
-					},
-					{
-						ProcessID:     "P2",
-						ArrivalTime:   6,
-						BurstDuration: 6,
-						Priority:      3,
-					},
-				},
-				title: "First-come, first-serve",
-			},
-			wantOut: loadFixture(t, "fcfs_fixture.txt"),
+			name: "subcommand",
+			args: "run rr example_processes.csv",
+			want: "Round-robin",
+		},
+		{
+			name: "flag before file",
+			args: "-sjf example_processes.csv",
+			want: "Shortest-job-first",
+		},
+		{
+			name: "flag after file",
+			args: "example_processes.csv -rr",
+			want: "Round-robin",
+		},
+		{
+			name:    "file without a scheduler flag",
+			args:    "example_processes.csv",
+			want:    "one scheduler flag must be set",
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			var w bytes.Buffer
-			FCFSSchedule(&w, tt.args.title, tt.args.processes)
-			if diff := cmp.Diff(w.String(), tt.wantOut); diff != "" {
-				t.Errorf(diff)
+			cmd := exec.Command(os.Args[0], "-test.run=^Test_main$")
+			cmd.Env = append(os.Environ(), "SCHED_MAIN_ARGS="+tt.args)
+			out, err := cmd.CombinedOutput()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("main() error = %v, wantErr %v\n%s", err, tt.wantErr, out)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("main() output doesn't contain %q:\n%s", tt.want, out)
 			}
 		})
 	}
 }
 
-func Test_loadProcesses(t *testing.T) {
+func Test_parseCLI(t *testing.T) {
 	t.Parallel()
-	type args struct {
-		r io.Reader
+	data := path.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(data, []byte("ProcessID,Burst Duration,Arrival Time,Priority\nP0,5,0,2\n"), 0o600); err != nil {
+		t.Fatal(err)
 	}
 	tests := []struct {
 		name    string
-		args    args
-		want    []Process
+		args    []string
+		piped   bool
+		want    config
 		wantErr error
 	}{
 		{
-			name: "bad CSV",
-			args: args{
-				r: iotest.ErrReader(io.ErrUnexpectedEOF),
-			},
-			wantErr: io.ErrUnexpectedEOF,
+			name: "flag before file",
+			args: []string{"-sjf", data},
+			want: config{algorithm: "sjf"},
+		},
+		{
+			name: "flags after file",
+			args: []string{data, "-rr", "-trace", "out.jsonl"},
+			want: config{algorithm: "rr", params: map[string]int64{"quantum": 1}, trace: "out.jsonl"},
+		},
+		{
+			name:  "dash reads stdin",
+			args:  []string{"-fcfs", "-"},
+			piped: true,
+			want:  config{algorithm: "fcfs"},
+		},
+		{
+			name:  "piped stdin",
+			args:  []string{"-sjfp"},
+			piped: true,
+			want:  config{algorithm: "sjfp"},
+		},
+		{
+			name:    "no scheduler",
+			args:    []string{data},
+			wantErr: sched.ErrInvalidArgs,
 		},
 		{
-			name: "success",
-			args: args{
-				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
-P0,5,0,2
-P1,9,3,1
-P2,6,3,3`),
-			},
-			want: []Process{
-				{
-					ProcessID:     "P0",
-					ArrivalTime:   0,
-					BurstDuration: 5,
-					Priority:      2,
-				},
-				{
-					ProcessID:     "P1",
-					ArrivalTime:   3,
-					BurstDuration: 9,
-					Priority:      1,
-				},
-				{
-					ProcessID:     "P2",
-					ArrivalTime:   3,
-					BurstDuration: 6,
-					Priority:      3,
-				},
-			},
+			name:    "two schedulers",
+			args:    []string{"-fcfs", "-rr", data},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "two files",
+			args:    []string{"-fcfs", data, data},
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "step needs a file",
+			args:    []string{"-fcfs", "-step", "-"},
+			piped:   true,
+			wantErr: sched.ErrInvalidArgs,
+		},
+		{
+			name:    "missing file",
+			args:    []string{"-fcfs", "missing.csv"},
+			wantErr: os.ErrNotExist,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := loadProcesses(tt.args.r)
-			if diff := cmp.Diff(got, tt.want); diff != "" {
-				t.Errorf(diff)
+			stdin := terminal(t)
+			if tt.piped {
+				stdin = pipe(t, "ProcessID,Burst Duration,Arrival Time,Priority\nP0,5,0,2\n")
 			}
+			flagSet := flag.NewFlagSet(tt.name, flag.ContinueOnError)
+			flagSet.SetOutput(io.Discard)
+			got, err := parseCLI(flagSet, tt.args, stdin)
 			if !errors.Is(err, tt.wantErr) {
-				t.Errorf("error = %v, want %v", err, tt.wantErr)
+				t.Fatalf("parseCLI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			data := got.data
+			t.Cleanup(func() { _ = data.Close() })
+			processes, err := sched.LoadProcesses(data)
+			if err != nil || len(processes) != 1 {
+				t.Errorf("sched.LoadProcesses() = %v, %v", processes, err)
+			}
+			got.data, got.unit = nil, 0
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(config{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf(diff)
 			}
 		})
 	}
 }
 
-func loadFixture(t *testing.T, p ...string) string {
-	b, err := os.ReadFile(path.Join(p...))
-	if err != nil {
-		t.Fail()
-	}
-
-	return string(b)
-}
-
-func Test_openProcessingFile1(t *testing.T) {
-	tmpFile, tErr := os.CreateTemp(t.TempDir(), "")
-	if tErr != nil {
-		t.Fatal(tErr)
-	}
-
-	type args struct {
-		args []string
-	}
+func Test_readData(t *testing.T) {
+	t.Parallel()
 	tests := []struct {
 		name    string
-		args    args
-		want    *os.File
-		wantErr bool
+		args    []string
+		stdin   func(t *testing.T) *os.File
+		wantErr error
 	}{
 		{
-			name: "success",
-			args: args{
-				args: []string{"binary_name", tmpFile.Name()},
-			},
-			want: tmpFile,
+			name:  "piped stdin",
+			stdin: func(t *testing.T) *os.File { return pipe(t, "piped") },
 		},
 		{
-			name: "not enough args",
-			args: args{
-				args: []string{"binary_name"},
-			},
-			wantErr: true,
+			name:    "terminal stdin",
+			stdin:   terminal,
+			wantErr: sched.ErrInvalidArgs,
 		},
 		{
-			name: "bad file",
-			args: args{
-				args: []string{"binary_name", "bad_file_name"},
-			},
-			wantErr: true,
+			name:  "dash",
+			args:  []string{"-"},
+			stdin: func(t *testing.T) *os.File { return pipe(t, "piped") },
 		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			got, closeFn, err := openProcessingFile(tt.args.args...)
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("openProcessingFile() error = %v, wantErr %v", err, tt.wantErr)
+			t.Parallel()
+			r, err := readData(tt.args, tt.stdin(t))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readData() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil {
 				return
 			}
-
-			if got == nil {
-				t.Fatal("file is unexpectedly nil")
+			b, err := io.ReadAll(r)
+			if err != nil || string(b) != "piped" {
+				t.Errorf("readData() read %q, %v", b, err)
 			}
-			if closeFn == nil {
-				t.Fatal("closeFn is unexpectedly nil")
-			}
-			t.Cleanup(closeFn)
-
-			f1, err := os.Stat(got.Name())
-			if err != nil {
-				t.Fatalf("Could not stat file: %v", got)
-			}
-			f2, err := os.Stat(tt.want.Name())
-			if err != nil {
-				t.Fatalf("Could not stat file: %v", tt.want)
-			}
-
-			if !os.SameFile(f1, f2) {
-				t.Fatal("files are not the same")
+			if err := r.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
 			}
 		})
 	}
 }
 
-func Test_outputGantt(t *testing.T) {
-	t.Parallel()
-	type args struct {
-		gantt []TimeSlice
+// pipe returns a stdin with data piped to it.
+func pipe(t *testing.T, data string) *os.File {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
 	}
-	tests := []struct {
-		name  string
-		args  args
-		wantW string
-	}{
-		{
-			name: "consecutive processes",
-			args: args{
-				gantt: []TimeSlice{
-					{PID: "A", Start: 1, Stop: 2},
-					{PID: "B", Start: 2, Stop: 4},
-					{PID: "C", Start: 4, Stop: 7},
-					{PID: "D", Start: 7, Stop: 11},
-					{PID: "E", Start: 11, Stop: 16},
-				},
-			},
-			wantW: `Gantt schedule
-|  A  |  B  |  C  |  D  |  E  |
-1     2     4     7     11    16
+	t.Cleanup(func() { _ = r.Close() })
+	go func() {
+		_, _ = io.WriteString(w, data)
+		_ = w.Close()
+	}()
 
-`,
-		},
-		{
-			name: "nonconsecutive processes",
-			args: args{
-				gantt: []TimeSlice{
-					{PID: "A", Start: 1, Stop: 2},
-					{PID: "B", Start: 5, Stop: 6},
-					{PID: "C", Start: 6, Stop: 7},
-					{PID: "D", Start: 9, Stop: 11},
-					{PID: "E", Start: 13, Stop: 16},
-				},
-			},
-			wantW: `Gantt schedule
-|  A  |  -  |  B  |  C  |  -  |  D  |  -  |  E  |
-1     2     5     6     7     9     11    13    16
+	return r
+}
 
-`,
-		},
+// terminal returns a stdin that is a character device, like an interactive terminal.
+func terminal(t *testing.T) *os.File {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skip(err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			w := &bytes.Buffer{}
-			outputGantt(w, tt.args.gantt)
-			if diff := cmp.Diff(tt.wantW, w.String()); diff != "" {
-				t.Errorf(diff)
-			}
-		})
+	t.Cleanup(func() { _ = f.Close() })
+
+	return f
+}
+
+// closeRecorder is data that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func Test_execute_closesData(t *testing.T) {
+	t.Parallel()
+	data := &closeRecorder{Reader: strings.NewReader("ProcessID,Burst Duration,Arrival Time,Priority\n1,5,0,2\n")}
+	if err := execute(config{algorithm: "nope", data: data}, nil, io.Discard); !errors.Is(err, sched.ErrInvalidArgs) {
+		t.Errorf("execute() error = %v, want %v", err, sched.ErrInvalidArgs)
+	}
+	if !data.closed {
+		t.Error("execute() left the data open")
 	}
 }
@@ -0,0 +1,130 @@
+package sched
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Test_crossCheck asserts known scheduling theory on random workloads, reporting the smallest
+// failing workload it can find as CSV.
+func Test_crossCheck(t *testing.T) {
+	t.Parallel()
+	averageWait := func(p Policy, processes []Process) float64 {
+		return Simulate(p, processes, nil).Result("").AverageWait
+	}
+	sameGantt := func(a, b Policy) func([]Process) bool {
+		return func(processes []Process) bool {
+			return slices.Equal(Simulate(a, processes, nil).gantt, Simulate(b, processes, nil).gantt)
+		}
+	}
+	tests := []struct {
+		name     string
+		mutate   func(processes []Process)
+		property func(processes []Process) bool
+	}{
+		{
+			name: "preemptive sjf minimizes average wait",
+			property: func(processes []Process) bool {
+				best := averageWait(sjf.policy(), processes)
+				for _, alg := range Algorithms() {
+					if averageWait(alg.Policy(alg.Defaults(), Machine{}), processes) < best-1e-9 {
+						return false
+					}
+				}
+				return true
+			},
+		},
+		{
+			name:     "rr with a quantum longer than every burst is fcfs",
+			property: sameGantt(Policy{quantum: 9}, fcfs.policy()),
+		},
+		{
+			name: "priority with equal priorities is sjf",
+			mutate: func(processes []Process) {
+				for i := range processes {
+					processes[i].Priority = 1
+				}
+			},
+			property: sameGantt(sjfp.policy(), sjf.policy()),
+		},
+	}
+	for i, tt := range tests {
+		i, tt := i, tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := rand.New(rand.NewPCG(4600, uint64(i)))
+			for n := 0; n < 500; n++ {
+				processes := randomWorkload(r, 1+r.IntN(10))
+				if tt.mutate != nil {
+					tt.mutate(processes)
+				}
+				if !tt.property(processes) {
+					var csv strings.Builder
+					_ = WriteProcesses(&csv, shrink(processes, tt.property))
+					t.Fatalf("counterexample:\n%s", csv.String())
+				}
+			}
+		})
+	}
+}
+
+// shrink greedily removes processes, then moves the whole workload earlier, then shortens
+// bursts and moves single arrivals earlier, for as long as the property still fails.
+func shrink(processes []Process, property func([]Process) bool) []Process {
+	fails := func(candidate []Process) bool { return len(candidate) > 0 && !property(candidate) }
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := range processes {
+			candidate := slices.Delete(slices.Clone(processes), i, i+1)
+			if fails(candidate) {
+				processes, shrunk = candidate, true
+				break
+			}
+		}
+		if earlier := slices.Clone(processes); !shrunk && slices.MinFunc(earlier, byArrivalTime).ArrivalTime > 0 {
+			for i := range earlier {
+				earlier[i].ArrivalTime--
+			}
+			if fails(earlier) {
+				processes, shrunk = earlier, true
+			}
+		}
+		for i := 0; i < len(processes) && !shrunk; i++ {
+			for _, edit := range []func(p *Process) bool{
+				func(p *Process) bool { p.BurstDuration--; return p.BurstDuration > 0 },
+				func(p *Process) bool { p.ArrivalTime--; return p.ArrivalTime >= 0 },
+			} {
+				candidate := slices.Clone(processes)
+				if edit(&candidate[i]) && fails(candidate) {
+					processes, shrunk = candidate, true
+					break
+				}
+			}
+		}
+	}
+
+	return processes
+}
+
+func Test_shrink(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: "A", ArrivalTime: 3, BurstDuration: 5},
+		{ProcessID: "B", ArrivalTime: 4, BurstDuration: 7},
+		{ProcessID: "C", ArrivalTime: 0, BurstDuration: 2},
+	}
+	// fails whenever some burst is at least 4.
+	got := shrink(processes, func(processes []Process) bool {
+		return !slices.ContainsFunc(processes, func(p Process) bool { return p.BurstDuration >= 4 })
+	})
+	want := []Process{{ProcessID: "B", ArrivalTime: 0, BurstDuration: 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shrink() = %v, want %v", got, want)
+	}
+}
+
+func byArrivalTime(a, b Process) int { return cmp.Compare(a.ArrivalTime, b.ArrivalTime) }
@@ -0,0 +1,153 @@
+package sched
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ProcessDiff is the timing of a single process in two schedules of the same workload.
+type ProcessDiff struct {
+	ProcessID string
+	A, B      ProcessResult
+}
+
+// DiffResults pairs up the processes of two schedules, in the order of a, which must be of
+// the same processes, each arriving at the same time with the same burst in both.
+func DiffResults(a, b Result) ([]ProcessDiff, error) {
+	if len(a.Rows) != len(b.Rows) {
+		return nil, fmt.Errorf("%w: %s has %d processes but %s has %d", ErrInvalidArgs, a.Title, len(a.Rows), b.Title, len(b.Rows))
+	}
+	index := make(map[string]int, len(b.Rows))
+	for i, r := range b.Rows {
+		index[r.ProcessID] = i
+	}
+	diffs := make([]ProcessDiff, len(a.Rows))
+	for i, r := range a.Rows {
+		j, ok := index[r.ProcessID]
+		if !ok {
+			return nil, fmt.Errorf("%w: process %q of %s isn't in %s", ErrInvalidArgs, r.ProcessID, a.Title, b.Title)
+		}
+		if o := b.Rows[j]; o.ArrivalTime != r.ArrivalTime || o.BurstDuration != r.BurstDuration {
+			return nil, fmt.Errorf("%w: process %q arrives at %d with a burst of %d in %s, but at %d with a burst of %d in %s",
+				ErrInvalidArgs, r.ProcessID, r.ArrivalTime, r.BurstDuration, a.Title, o.ArrivalTime, o.BurstDuration, b.Title)
+		}
+		diffs[i] = ProcessDiff{ProcessID: r.ProcessID, A: r, B: b.Rows[j]}
+	}
+
+	return diffs, nil
+}
+
+//region Output helpers
+
+// OutputDiff outputs how every process fares when switching from schedule a to schedule b:
+// both Gantt charts on a shared time axis, then the change in each process's timing.
+func OutputDiff(w io.Writer, a, b Result) error {
+	diffs, err := DiffResults(a, b)
+	if err != nil {
+		return err
+	}
+	if a.Timescale != b.Timescale {
+		return fmt.Errorf("%w: %s and %s are on different timescales", ErrInvalidArgs, a.Title, b.Title)
+	}
+	ts := a.Timescale
+	OutputTitle(w, a.Title+" vs "+b.Title)
+	outputStackedGantt(w, "Gantt schedules", ts, a, b)
+
+	_, _ = fmt.Fprintln(w, "Schedule diff")
+	table := tablewriter.NewWriter(w)
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.SetHeader([]string{"ID", "Wait", "Δ Wait", "Turnaround", "Δ Turnaround", "Exit", "Δ Exit"})
+	var better, worse int
+	for _, d := range diffs {
+		table.Append([]string{
+			d.ProcessID,
+			ts.FormatTime(d.A.Wait) + " → " + ts.FormatTime(d.B.Wait),
+			signed(ts, d.B.Wait-d.A.Wait),
+			ts.FormatTime(d.A.Turnaround) + " → " + ts.FormatTime(d.B.Turnaround),
+			signed(ts, d.B.Turnaround-d.A.Turnaround),
+			ts.FormatTime(d.A.Exit) + " → " + ts.FormatTime(d.B.Exit),
+			signed(ts, d.B.Exit-d.A.Exit),
+		})
+		switch {
+		case d.B.Turnaround < d.A.Turnaround:
+			better++
+		case d.B.Turnaround > d.A.Turnaround:
+			worse++
+		}
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "Average wait: %s → %s (%s)%s\n", ts.FormatMean(a.AverageWait), ts.FormatMean(b.AverageWait),
+		ts.FormatChange(b.AverageWait-a.AverageWait), ts.unitSuffix())
+	_, _ = fmt.Fprintf(w, "Average turnaround: %s → %s (%s)%s\n", ts.FormatMean(a.AverageTurnaround), ts.FormatMean(b.AverageTurnaround),
+		ts.FormatChange(b.AverageTurnaround-a.AverageTurnaround), ts.unitSuffix())
+	_, _ = fmt.Fprintf(w, "Throughput: %s → %s (%+.2f)\n", ts.FormatRate(a.Throughput), ts.FormatRate(b.Throughput),
+		(b.Throughput-a.Throughput)*float64(ts.ticks()))
+	_, _ = fmt.Fprintf(w, "%d of %d processes finish sooner under %s, %d later, %d the same\n",
+		better, len(diffs), b.Title, worse, len(diffs)-better-worse)
+
+	return nil
+}
+
+// signed formats a change in time, leaving no change blank.
+func signed(ts Timescale, delta int64) string {
+	switch {
+	case delta == 0:
+		return ""
+	case delta > 0:
+		return "+" + ts.FormatTime(delta)
+	default:
+		return ts.FormatTime(delta)
+	}
+}
+
+// outputStackedGantt outputs the Gantt charts of several schedules under a heading, one above
+// the other, split at every time any of them switches process so that the columns line up.
+func outputStackedGantt(w io.Writer, heading string, ts Timescale, results ...Result) {
+	var times []int64
+	widest, label := 0, 0
+	for _, res := range results {
+		for _, slice := range res.Gantt {
+			times = append(times, slice.Start, slice.Stop)
+			widest = max(widest, len(slice.PID))
+		}
+		label = max(label, len(res.Title))
+	}
+	if len(times) == 0 {
+		return
+	}
+	slices.Sort(times)
+	times = slices.Compact(times)
+	for _, t := range times {
+		widest = max(widest, len(ts.FormatTime(t)))
+	}
+	_, _ = fmt.Fprintln(w, heading)
+
+	for _, res := range results {
+		_, _ = fmt.Fprintf(w, "%-*s |", label, res.Title)
+		next := 0 // the first slice that hasn't stopped
+		for i := range times[:len(times)-1] {
+			for next < len(res.Gantt) && res.Gantt[next].Stop <= times[i] {
+				next++
+			}
+			pid := ""
+			if next < len(res.Gantt) && res.Gantt[next].Start <= times[i] {
+				pid = res.Gantt[next].PID
+			}
+			_, _ = fmt.Fprintf(w, " %-*s |", widest, pid)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	var axis strings.Builder
+	axis.WriteString(strings.Repeat(" ", label+1))
+	for _, t := range times {
+		_, _ = fmt.Fprintf(&axis, "%-*s", widest+3, ts.FormatTime(t))
+	}
+	_, _ = fmt.Fprint(w, strings.TrimRight(axis.String(), " "), "\n\n")
+}
+
+//endregion
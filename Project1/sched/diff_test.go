@@ -0,0 +1,94 @@
+package sched
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_outputStackedGantt(t *testing.T) {
+	t.Parallel()
+	fcfs := Result{Title: "FCFS", Gantt: []TimeSlice{
+		{PID: "A", Start: 0, Stop: 3},
+		{PID: "B", Start: 3, Stop: 4},
+	}}
+	rr := Result{Title: "RR", Gantt: []TimeSlice{
+		{PID: "A", Start: 0, Stop: 1},
+		{PID: "B", Start: 1, Stop: 2},
+		{PID: "A", Start: 2, Stop: 4},
+		{PID: "C", Start: 12, Stop: 13},
+	}}
+	w := &bytes.Buffer{}
+	outputStackedGantt(w, "Gantt schedules", Timescale{}, fcfs, rr)
+	want := `Gantt schedules
+FCFS | A  | A  | A  | B  |    |    |
+RR   | A  | B  | A  | A  |    | C  |
+     0    1    2    3    4    12   13
+
+`
+	if diff := cmp.Diff(want, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	t.Parallel()
+	row := func(id string, wait int64) ProcessResult {
+		return ProcessResult{Process: Process{ProcessID: id}, Wait: wait}
+	}
+	tests := []struct {
+		name    string
+		a, b    Result
+		want    []ProcessDiff
+		wantErr error
+	}{
+		{
+			name: "matched by ID",
+			a:    Result{Rows: []ProcessResult{row("A", 0), row("B", 3)}},
+			b:    Result{Rows: []ProcessResult{row("B", 1), row("A", 2)}},
+			want: []ProcessDiff{
+				{ProcessID: "A", A: row("A", 0), B: row("A", 2)},
+				{ProcessID: "B", A: row("B", 3), B: row("B", 1)},
+			},
+		},
+		{
+			name:    "different counts",
+			a:       Result{Rows: []ProcessResult{row("A", 0)}},
+			b:       Result{Rows: []ProcessResult{row("A", 0), row("B", 0)}},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "different processes",
+			a:       Result{Rows: []ProcessResult{row("A", 0)}},
+			b:       Result{Rows: []ProcessResult{row("B", 0)}},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "different arrivals",
+			a:       Result{Rows: []ProcessResult{row("A", 0)}},
+			b:       Result{Rows: []ProcessResult{{Process: Process{ProcessID: "A", ArrivalTime: 1}}}},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "different bursts",
+			a:       Result{Rows: []ProcessResult{row("A", 0)}},
+			b:       Result{Rows: []ProcessResult{{Process: Process{ProcessID: "A", BurstDuration: 1}}}},
+			wantErr: ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := DiffResults(tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DiffResults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
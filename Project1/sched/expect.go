@@ -0,0 +1,245 @@
+package sched
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// Workload is a workload file: its processes, and the results it expects of algorithms.
+	Workload struct {
+		Processes    []Process
+		Expectations []Expectation
+	}
+
+	// Expectation is a result a workload expects of an algorithm, so that a workload file can
+	// carry its own test case. Only the results given are checked.
+	Expectation struct {
+		Algorithm         string
+		Params            map[string]int64 // overriding the algorithm's defaults, as on the command line
+		AverageWait       *float64         // in ticks
+		AverageTurnaround *float64         // in ticks
+		Throughput        *float64         // per tick
+		Gantt             []TimeSlice      // the full schedule
+		Line              int              // of the workload file, when read from one
+	}
+)
+
+var ErrExpectation = errors.New("expectation failed")
+
+// ParseExpectation parses an algorithm's name or policy expression, in double quotes if it has
+// spaces, followed by the results expected of it, as space separated <key>=<value> pairs:
+// • wait, turnaround and throughput are averages, written as in the output of a schedule
+// • gantt is the full schedule, written as comma separated <pid>:<start>-<stop> slices
+// • any other key is a param of the algorithm
+func ParseExpectation(s string, ts Timescale) (Expectation, error) {
+	var name string
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return Expectation{}, fmt.Errorf("%w: %s has no closing quote", ErrInvalidArgs, s)
+		}
+		name, _ = strconv.Unquote(quoted)
+		s = s[len(quoted):]
+	} else if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		name, s = s[:i], s[i:]
+	} else {
+		name, s = s, ""
+	}
+	if strings.TrimSpace(name) == "" {
+		return Expectation{}, fmt.Errorf("%w: expectation names no algorithm", ErrInvalidArgs)
+	}
+	alg, err := LookupAlgorithm(name)
+	if err != nil {
+		return Expectation{}, err
+	}
+	e := Expectation{Algorithm: alg.Name}
+	average := func(value string, perTick bool) (*float64, error) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q isn't a number", ErrInvalidArgs, value)
+		}
+		if perTick {
+			v /= float64(ts.ticks())
+		} else {
+			v *= float64(ts.ticks())
+		}
+		return &v, nil
+	}
+	for _, field := range strings.Fields(s) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Expectation{}, fmt.Errorf("%w: %q isn't <key>=<value>", ErrInvalidArgs, field)
+		}
+		switch key {
+		case "wait":
+			e.AverageWait, err = average(value, false)
+		case "turnaround":
+			e.AverageTurnaround, err = average(value, false)
+		case "throughput":
+			e.Throughput, err = average(value, true)
+		case "gantt":
+			e.Gantt, err = parseGantt(value, ts)
+		default:
+			if !alg.hasParam(key) {
+				return Expectation{}, fmt.Errorf("%w: %s expects no %q", ErrInvalidArgs, alg.Name, key)
+			}
+			if e.Params == nil {
+				e.Params = alg.Defaults()
+			}
+			if e.Params[key], err = strconv.ParseInt(value, 10, 64); err != nil {
+				err = fmt.Errorf("%w: -%s: %q isn't an integer", ErrInvalidArgs, key, value)
+			}
+		}
+		if err != nil {
+			return Expectation{}, err
+		}
+	}
+	if e.Params != nil {
+		if err := alg.Validate(e.Params); err != nil {
+			return Expectation{}, err
+		}
+	}
+
+	return e, nil
+}
+
+// parseGantt parses comma separated <pid>:<start>-<stop> slices, merging a process's adjacent
+// slices as a simulation records them.
+func parseGantt(s string, ts Timescale) ([]TimeSlice, error) {
+	var gantt []TimeSlice
+	for _, entry := range strings.Split(s, ",") {
+		pid, span, ok := strings.Cut(entry, ":")
+		start, stop, ok2 := strings.Cut(span, "-")
+		if !ok || !ok2 || pid == "" {
+			return nil, fmt.Errorf("%w: slice %q isn't <pid>:<start>-<stop>", ErrInvalidArgs, entry)
+		}
+		slice := TimeSlice{PID: pid}
+		var err error
+		if slice.Start, err = ts.ParseTime(start); err != nil {
+			return nil, fmt.Errorf("%w: slice %q", err, entry)
+		}
+		if slice.Stop, err = ts.ParseTime(stop); err != nil {
+			return nil, fmt.Errorf("%w: slice %q", err, entry)
+		}
+		if n := len(gantt); n > 0 && gantt[n-1].PID == pid && gantt[n-1].Stop == slice.Start {
+			gantt[n-1].Stop = slice.Stop
+			continue
+		}
+		gantt = append(gantt, slice)
+	}
+	return gantt, nil
+}
+
+// String names the algorithm expected of, with any params set.
+func (e Expectation) String() string {
+	name := e.Algorithm
+	if strings.Contains(name, " ") {
+		name = strconv.Quote(name)
+	}
+	alg, err := LookupAlgorithm(e.Algorithm)
+	if err != nil || e.Params == nil {
+		return name
+	}
+	for _, p := range alg.Params {
+		name += fmt.Sprintf(" %s=%d", p.Name, e.Params[p.Name])
+	}
+	return name
+}
+
+func (a Algorithm) hasParam(name string) bool {
+	for _, p := range a.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Check runs the expected algorithm over processes on machine m, returning its schedule and
+// every way it differs from the expectation.
+func (e Expectation) Check(processes []Process, m Machine) (Result, []string, error) {
+	alg, err := LookupAlgorithm(e.Algorithm)
+	if err != nil {
+		return Result{}, nil, err
+	}
+	params := e.Params
+	if params == nil {
+		params = alg.Defaults()
+	}
+	res, err := Run(alg, params, m, processes)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	ts := m.Clock
+	var mismatches []string
+	mean := func(name string, got float64, want *float64) {
+		if want != nil && ts.FormatMean(got) != ts.FormatMean(*want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s, want %s", name, ts.FormatMean(got), ts.FormatMean(*want)))
+		}
+	}
+	mean("average wait", res.AverageWait, e.AverageWait)
+	mean("average turnaround", res.AverageTurnaround, e.AverageTurnaround)
+	if e.Throughput != nil && ts.FormatRate(res.Throughput) != ts.FormatRate(*e.Throughput) {
+		mismatches = append(mismatches, fmt.Sprintf("throughput is %s, want %s", ts.FormatRate(res.Throughput), ts.FormatRate(*e.Throughput)))
+	}
+	if e.Gantt != nil && !sameSlices(res.Gantt, e.Gantt) {
+		mismatches = append(mismatches, "Gantt schedule differs")
+	}
+
+	return res, mismatches, nil
+}
+
+// sameSlices compares who ran when, ignoring the CPU's speed.
+func sameSlices(got, want []TimeSlice) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].PID != want[i].PID || got[i].Start != want[i].Start || got[i].Stop != want[i].Stop {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckExpectations checks every expectation of a workload on machine m, outputting whether
+// each passed and how failing ones differ, and returns ErrExpectation if any failed.
+func CheckExpectations(w io.Writer, workload Workload, m Machine) error {
+	if len(workload.Expectations) == 0 {
+		return fmt.Errorf("%w: the workload expects no results (add #expect lines)", ErrInvalidArgs)
+	}
+	failed := 0
+	for _, e := range workload.Expectations {
+		res, mismatches, err := e.Check(workload.Processes, m)
+		if err != nil {
+			return fmt.Errorf("%w: line %d", err, e.Line)
+		}
+		name := e.String()
+		if len(mismatches) == 0 {
+			_, _ = fmt.Fprintf(w, "PASS %s (line %d)\n", name, e.Line)
+			continue
+		}
+		failed++
+		_, _ = fmt.Fprintf(w, "FAIL %s (line %d)\n", name, e.Line)
+		for _, mismatch := range mismatches {
+			_, _ = fmt.Fprintf(w, "  %s\n", mismatch)
+		}
+		if e.Gantt != nil && !sameSlices(res.Gantt, e.Gantt) {
+			outputStackedGantt(w, "Gantt schedules", res.Timescale,
+				Result{Title: "want", Gantt: e.Gantt}, Result{Title: "got", Gantt: res.Gantt})
+		}
+	}
+	_, _ = fmt.Fprintf(w, "%d of %d expectations passed\n", len(workload.Expectations)-failed, len(workload.Expectations))
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrExpectation, failed, len(workload.Expectations))
+	}
+
+	return nil
+}
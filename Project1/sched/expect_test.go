@@ -0,0 +1,120 @@
+package sched
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseExpectation(t *testing.T) {
+	t.Parallel()
+	float := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name    string
+		s       string
+		ts      Timescale
+		want    Expectation
+		wantErr error
+	}{
+		{
+			name: "averages",
+			s:    " fcfs wait=3.33 turnaround=10 throughput=0.15",
+			want: Expectation{Algorithm: "fcfs", AverageWait: float(3.33), AverageTurnaround: float(10), Throughput: float(0.15)},
+		},
+		{
+			name: "scaled",
+			s:    "rr quantum=2 wait=0.5 gantt=A:0-0.5,A:0.5-1.5,B:1.5-2",
+			ts:   Timescale{Decimals: 1},
+			want: Expectation{
+				Algorithm:   "rr",
+				Params:      map[string]int64{"quantum": 2},
+				AverageWait: float(5),
+				Gantt:       []TimeSlice{{PID: "A", Start: 0, Stop: 15}, {PID: "B", Start: 15, Stop: 20}},
+			},
+		},
+		{
+			name: "quoted policy expression",
+			s:    `"priority asc, remaining asc; preemptive" wait=2.2 gantt=A:0-1`,
+			want: Expectation{
+				Algorithm:   "priority asc, remaining asc; preemptive",
+				AverageWait: float(2.2),
+				Gantt:       []TimeSlice{{PID: "A", Start: 0, Stop: 1}},
+			},
+		},
+		{
+			name:    "unclosed quote",
+			s:       `"priority asc, remaining asc wait=2.2`,
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "no algorithm",
+			s:       " ",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "unknown algorithm",
+			s:       "lottery wait=1",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "unknown key",
+			s:       "fcfs quantum=2",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "param out of bounds",
+			s:       "rr quantum=0",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "bad slice",
+			s:       "fcfs gantt=A:0",
+			wantErr: ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseExpectation(tt.s, tt.ts)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf(diff)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Test_checkExpectations checks the results expected by every workload in testdata/workloads.
+func Test_checkExpectations(t *testing.T) {
+	t.Parallel()
+	workloads, err := filepath.Glob(filepath.Join("testdata", "workloads", "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range workloads {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = f.Close() })
+			workload, err := LoadWorkload(f, Timescale{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var w bytes.Buffer
+			if err := CheckExpectations(&w, workload, Machine{}); err != nil {
+				t.Errorf("%v:\n%s", err, w.String())
+			}
+		})
+	}
+}
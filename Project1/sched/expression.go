@@ -0,0 +1,110 @@
+package sched
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// expressionKeys are the keys a policy expression can order the ready queue by.
+var expressionKeys = []sortKey{byArrival, byBurst, byRemaining, byCPU, byPriority}
+
+// isExpression reports whether name is a policy expression rather than an algorithm's name.
+func isExpression(name string) bool {
+	return strings.ContainsAny(name, " ,;") || name == "fifo" ||
+		slices.ContainsFunc(expressionKeys, func(k sortKey) bool { return k.name == name })
+}
+
+// ParseExpression parses a policy written as semicolon separated clauses:
+// • the order of the ready queue, as comma separated <key> [asc|desc] terms, with keys
+// arrival, burst, remaining, cpu (received so far) and priority, or fifo alone; ties are
+// broken FIFO
+// • preemptive, to preempt the running process when a better one is ready
+// • quantum <n>, to preempt it after a time slice of n units
+// For example: priority asc, remaining asc, arrival asc; preemptive
+func ParseExpression(expr string) (Algorithm, error) {
+	var (
+		keys       []sortKey
+		terms      []string
+		preemptive bool
+		quantum    int64
+		ordered    bool
+	)
+	for _, clause := range strings.Split(expr, ";") {
+		fields := strings.Fields(clause)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 1 && fields[0] == "preemptive":
+			preemptive = true
+			continue
+		case fields[0] == "quantum":
+			var err error
+			if len(fields) != 2 {
+				return Algorithm{}, fmt.Errorf("%w: %q isn't quantum <n>", ErrInvalidArgs, strings.TrimSpace(clause))
+			}
+			if quantum, err = strconv.ParseInt(fields[1], 10, 64); err != nil || quantum < 1 {
+				return Algorithm{}, fmt.Errorf("%w: quantum %q isn't a positive integer", ErrInvalidArgs, fields[1])
+			}
+			continue
+		case ordered:
+			return Algorithm{}, fmt.Errorf("%w: %q orders the ready queue a second time", ErrInvalidArgs, strings.TrimSpace(clause))
+		}
+		ordered = true
+		if len(fields) == 1 && fields[0] == "fifo" {
+			continue
+		}
+		for _, term := range strings.Split(clause, ",") {
+			fields := strings.Fields(term)
+			if len(fields) == 0 || len(fields) > 2 {
+				return Algorithm{}, fmt.Errorf("%w: %q isn't <key> [asc|desc]", ErrInvalidArgs, strings.TrimSpace(term))
+			}
+			i := slices.IndexFunc(expressionKeys, func(k sortKey) bool { return k.name == fields[0] })
+			if i < 0 {
+				return Algorithm{}, fmt.Errorf("%w: unknown key %q, expected one of arrival, burst, remaining, cpu or priority", ErrInvalidArgs, fields[0])
+			}
+			if slices.ContainsFunc(keys, func(k sortKey) bool { return k.name == fields[0] }) {
+				return Algorithm{}, fmt.Errorf("%w: the ready queue is ordered by %s twice", ErrInvalidArgs, fields[0])
+			}
+			key, direction := expressionKeys[i], "asc"
+			if len(fields) == 2 {
+				direction = fields[1]
+			}
+			switch direction {
+			case "asc":
+			case "desc":
+				key.desc = true
+			default:
+				return Algorithm{}, fmt.Errorf("%w: %q isn't asc or desc", ErrInvalidArgs, direction)
+			}
+			keys = append(keys, key)
+			terms = append(terms, key.name+" "+direction)
+		}
+	}
+
+	// the name is the expression written out in full, so that it reads the same everywhere.
+	clauses := []string{strings.Join(terms, ", ")}
+	if len(terms) == 0 {
+		clauses[0] = "fifo"
+	}
+	if preemptive {
+		clauses = append(clauses, "preemptive")
+	}
+	alg := Algorithm{Usage: "Policy expression"}
+	if quantum > 0 {
+		clauses = append(clauses, fmt.Sprintf("quantum %d", quantum))
+		alg.Params = []Param{{Name: "quantum", Usage: "time slice length", Default: quantum, Min: 1, Time: true}}
+	}
+	alg.Name = strings.Join(clauses, "; ")
+	alg.Title = "Policy: " + alg.Name
+	alg.policy = func(params map[string]int64) Policy {
+		return Policy{
+			keys:       slices.Clone(keys),
+			preemptive: preemptive,
+			quantum:    params["quantum"],
+		}
+	}
+
+	return alg, nil
+}
@@ -0,0 +1,80 @@
+package sched
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func Test_parseExpression(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		expr     string
+		wantName string
+		wantErr  error
+	}{
+		{expr: "priority asc, remaining asc, arrival asc; preemptive", wantName: "priority asc, remaining asc, arrival asc; preemptive"},
+		{expr: " burst desc ;quantum 2", wantName: "burst desc; quantum 2"},
+		{expr: "preemptive; cpu", wantName: "cpu asc; preemptive"},
+		{expr: "fifo; quantum 1", wantName: "fifo; quantum 1"},
+		{expr: "quantum 3", wantName: "fifo; quantum 3"},
+		{expr: "deadline asc", wantErr: ErrInvalidArgs},
+		{expr: "priority up", wantErr: ErrInvalidArgs},
+		{expr: "priority, priority desc", wantErr: ErrInvalidArgs},
+		{expr: "priority; remaining", wantErr: ErrInvalidArgs},
+		{expr: "arrival; quantum 0", wantErr: ErrInvalidArgs},
+		{expr: "arrival; quantum", wantErr: ErrInvalidArgs},
+		{expr: "arrival,", wantErr: ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			got, err := LookupAlgorithm(tt.expr)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", got.Name, tt.wantName)
+			}
+			if err != nil {
+				return
+			}
+			// the name is itself the expression.
+			if again, err := ParseExpression(got.Name); err != nil || again.Name != got.Name {
+				t.Errorf("reparsing %q = %q, %v", got.Name, again.Name, err)
+			}
+		})
+	}
+}
+
+// Test_parseExpression_builtins checks expressions of the built-in schedulers schedule alike.
+func Test_parseExpression_builtins(t *testing.T) {
+	t.Parallel()
+	tests := map[scheduler]string{
+		fcfs: "arrival",
+		sjf:  "remaining; preemptive",
+		sjfp: "priority, remaining; preemptive",
+		rr:   "fifo; quantum 1",
+	}
+	for scheduler, expr := range tests {
+		scheduler, expr := scheduler, expr
+		t.Run(scheduler.String(), func(t *testing.T) {
+			t.Parallel()
+			alg, err := ParseExpression(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewPCG(4606, uint64(scheduler)))
+			for i := 0; i < 200; i++ {
+				processes := randomWorkload(r, 1+r.IntN(10))
+				want := Simulate(scheduler.policy(), processes, nil).gantt
+				got := Simulate(alg.Policy(alg.Defaults(), Machine{}), processes, nil).gantt
+				if !slices.Equal(got, want) {
+					t.Fatalf("workload %+v:\ngot  %v\nwant %v", processes, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package sched
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+type (
+	// GangModel is a machine of several CPUs, which gang scheduling switches between gangs
+	// every Quantum, in whole units of Clock.
+	GangModel struct {
+		CPUs    int
+		Quantum int64
+		Clock   Timescale
+	}
+
+	// GangResult is a schedule of gangs on several CPUs.
+	GangResult struct {
+		Result
+		CPUs        [][]TimeSlice `json:"cpus"`        // Gantt chart of each CPU
+		Utilization float64       `json:"utilization"` // of all CPUs, from the first arrival to the last exit
+	}
+
+	// gang is the processes of a group, which only ever run together.
+	gang struct {
+		name       string
+		members    []*task
+		arrival    int64 // of its last process
+		unfinished int
+	}
+)
+
+func (gm GangModel) Validate() error {
+	switch {
+	case gm.CPUs < 1:
+		return fmt.Errorf("%w: gang scheduling needs at least one CPU", ErrInvalidArgs)
+	case gm.Quantum < 1:
+		return fmt.Errorf("%w: the gang quantum must be at least 1", ErrInvalidArgs)
+	}
+	return gm.Clock.Validate()
+}
+
+// SimulateGang runs processes on the CPUs of gm by gang scheduling: the processes of a group
+// are a gang, whose unfinished processes only ever run together, each on a CPU of its own.
+// A gang arrives once all its processes have. Every quantum, the gangs that have arrived are
+// packed onto the CPUs in round-robin order: the gang at the head of the queue always runs, and
+// each gang after it runs too if it fits on the CPUs left over. Gangs that ran then go to the
+// back of the queue, behind those that didn't fit. A slot ends early once every process running
+// in it finishes, but a CPU whose process finishes sooner idles until the slot ends.
+func SimulateGang(gm GangModel, processes []Process, title string) (GangResult, error) {
+	if err := gm.Validate(); err != nil {
+		return GangResult{}, err
+	}
+	var (
+		gangs   []*gang
+		byKey   = make(map[groupKey]*gang)
+		tasks   = make([]*task, len(processes))
+		quantum = gm.Quantum * gm.Clock.ticks()
+		cpus    = make([][]TimeSlice, gm.CPUs)
+	)
+	for i, p := range processes {
+		if len(p.Locks) > 0 || len(p.IO) > 0 || p.Memory != 0 {
+			return GangResult{}, fmt.Errorf("%w: process %s has locks, blocking calls or memory, which aren't simulated for gangs", ErrInvalidArgs, p.ProcessID)
+		}
+		key := p.group()
+		g := byKey[key]
+		if g == nil {
+			g = &gang{name: key.name, arrival: p.ArrivalTime}
+			byKey[key] = g
+			gangs = append(gangs, g)
+		}
+		tasks[i] = &task{Process: p, index: i, remaining: p.BurstDuration, prio: p.Priority}
+		g.members = append(g.members, tasks[i])
+		g.arrival = max(g.arrival, p.ArrivalTime)
+		if p.BurstDuration > 0 {
+			g.unfinished++
+		}
+	}
+	for _, g := range gangs {
+		if len(g.members) > gm.CPUs {
+			return GangResult{}, fmt.Errorf("%w: gang %s has %d processes, more than the %d CPUs", ErrInvalidArgs, g.name, len(g.members), gm.CPUs)
+		}
+	}
+	arrivals := slices.Clone(gangs)
+	slices.SortStableFunc(arrivals, func(a, b *gang) int { return cmp.Compare(a.arrival, b.arrival) })
+
+	var (
+		queue    []*gang // arrived gangs with unfinished processes, in round-robin order
+		next     int     // next entry in arrivals
+		finished int     // gangs
+		now      int64
+		busy     int64 // CPU time given
+	)
+	if len(arrivals) > 0 {
+		now = arrivals[0].arrival
+	}
+	first := now
+	// record adds a slice to the chart of a CPU, extending its last slice if it continues it.
+	record := func(cpu int, pid string, start, stop int64) {
+		if n := len(cpus[cpu]); n > 0 && cpus[cpu][n-1].PID == pid && cpus[cpu][n-1].Stop == start {
+			cpus[cpu][n-1].Stop = stop
+			return
+		}
+		cpus[cpu] = append(cpus[cpu], TimeSlice{PID: pid, Start: start, Stop: stop})
+	}
+	for finished < len(gangs) {
+		for next < len(arrivals) && arrivals[next].arrival <= now {
+			g := arrivals[next]
+			next++
+			// processes with nothing to run finish as their gang arrives.
+			for _, t := range g.members {
+				if t.remaining == 0 {
+					t.exit = now
+				}
+			}
+			if g.unfinished == 0 {
+				finished++
+				continue
+			}
+			queue = append(queue, g)
+		}
+		if len(queue) == 0 {
+			if next < len(arrivals) {
+				now = arrivals[next].arrival
+			}
+			continue
+		}
+
+		// pack the gangs onto the CPUs, and run the slot.
+		var ran, waiting []*gang
+		free, slot := gm.CPUs, int64(0)
+		for _, g := range queue {
+			if g.unfinished > free {
+				waiting = append(waiting, g)
+				continue
+			}
+			free -= g.unfinished
+			ran = append(ran, g)
+			for _, t := range g.members {
+				slot = max(slot, min(t.remaining, quantum))
+			}
+		}
+		cpu := 0
+		for _, g := range ran {
+			for _, t := range g.members {
+				if t.remaining == 0 {
+					continue
+				}
+				run := min(t.remaining, slot)
+				record(cpu, t.ProcessID, now, now+run)
+				cpu++
+				busy += run
+				t.remaining -= run
+				if t.remaining == 0 {
+					t.exit = now + run
+					g.unfinished--
+				}
+			}
+		}
+		now += slot
+
+		queue = waiting
+		for _, g := range ran {
+			if g.unfinished == 0 {
+				finished++
+				continue
+			}
+			queue = append(queue, g)
+		}
+	}
+
+	rows := make([]ProcessResult, len(tasks))
+	var all []TimeSlice
+	last := first
+	for i, t := range tasks {
+		turnaround := t.exit - t.ArrivalTime
+		rows[i] = ProcessResult{
+			Process:    t.Process,
+			Wait:       turnaround - t.BurstDuration,
+			Turnaround: turnaround,
+			Exit:       t.exit,
+		}
+		last = max(last, t.exit)
+	}
+	for _, chart := range cpus {
+		all = append(all, chart...)
+	}
+	res := GangResult{Result: Summarize(title, nil, rows), CPUs: cpus}
+	res.Groups, res.Timescale = groupShares(rows, all), gm.Clock
+	if last > first {
+		res.Utilization = float64(busy) / float64(int64(gm.CPUs)*(last-first))
+	}
+
+	return res, nil
+}
+
+// OutputGangResult outputs a gang schedule, the chart of every CPU above the others, followed
+// by the share of the CPUs each gang received.
+func OutputGangResult(w io.Writer, res GangResult) {
+	ts := res.Timescale
+	OutputTitle(w, res.Title)
+	charts := make([]Result, len(res.CPUs))
+	for i, chart := range res.CPUs {
+		charts[i] = Result{Title: "CPU " + strconv.Itoa(i), Gantt: chart}
+	}
+	outputStackedGantt(w, "Gantt schedule, by CPU", ts, charts...)
+	header, rows := scheduleTable(res.Result)
+	outputSchedule(w, header, rows, ts, res.AverageWait, res.AverageTurnaround, res.Throughput)
+	_, _ = fmt.Fprintf(w, "CPU utilization: %.1f%%\n", 100*res.Utilization)
+	outputGroups(w, res.Groups, ts)
+}
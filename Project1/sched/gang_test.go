@@ -0,0 +1,151 @@
+package sched
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// gangs is a gang of two processes, one of three, and a process of its own.
+var gangs = []Process{
+	{ProcessID: "A1", Group: "A", BurstDuration: 2},
+	{ProcessID: "A2", Group: "A", BurstDuration: 3},
+	{ProcessID: "B1", Group: "B", BurstDuration: 1},
+	{ProcessID: "B2", Group: "B", BurstDuration: 1, ArrivalTime: 1},
+	{ProcessID: "B3", Group: "B", BurstDuration: 2},
+	{ProcessID: "C", BurstDuration: 2, ArrivalTime: 1},
+}
+
+func Test_simulateGang(t *testing.T) {
+	t.Parallel()
+	res, err := SimulateGang(GangModel{CPUs: 3, Quantum: 1}, gangs, "gang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A runs alone until B has all arrived at 1. B then needs every CPU, so C runs beside A
+	// first, and B next, ahead of them; once A1 and B1 finish, the rest of all three fit at once.
+	wantCPUs := [][]TimeSlice{
+		{{PID: "A1", Start: 0, Stop: 2}, {PID: "B1", Start: 2, Stop: 3}, {PID: "A2", Start: 3, Stop: 4}},
+		{{PID: "A2", Start: 0, Stop: 2}, {PID: "B2", Start: 2, Stop: 3}, {PID: "C", Start: 3, Stop: 4}},
+		{{PID: "C", Start: 1, Stop: 2}, {PID: "B3", Start: 2, Stop: 4}},
+	}
+	if diff := cmp.Diff(wantCPUs, res.CPUs); diff != "" {
+		t.Errorf(diff)
+	}
+	wantGroups := []GroupShare{
+		{Group: "A", Processes: 2, CPU: 5, Contended: 5, Share: 5.0 / 11},
+		{Group: "B", Processes: 3, CPU: 4, Contended: 4, Share: 4.0 / 11},
+		{Group: "C", Processes: 1, CPU: 2, Contended: 2, Share: 2.0 / 11},
+	}
+	if diff := cmp.Diff(wantGroups, res.Groups); diff != "" {
+		t.Errorf(diff)
+	}
+	if want := 11.0 / 12; res.Utilization != want {
+		t.Errorf("utilization = %v, want %v", res.Utilization, want)
+	}
+}
+
+// Test_simulateGang_random checks random gang schedules: every process receives its burst
+// once it has arrived, a CPU runs one process at a time, and a gang's processes only run
+// together.
+func Test_simulateGang_random(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewPCG(3700, 0))
+	checked := 0
+	for i := 0; i < 300; i++ {
+		processes := randomWorkload(r, 1+r.IntN(10))
+		for j := range processes {
+			processes[j].Group = fmt.Sprint("G", r.IntN(4))
+		}
+		gm := GangModel{CPUs: 1 + r.IntN(4), Quantum: 1 + r.Int64N(3)}
+		res, err := SimulateGang(gm, processes, "gang")
+		if errors.Is(err, ErrInvalidArgs) {
+			continue // a gang larger than the machine
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		checked++
+		received := make(map[string]int64)
+		running := make(map[int64]map[string]bool) // processes running at each tick
+		for _, chart := range res.CPUs {
+			for k, slice := range chart {
+				if k > 0 && slice.Start < chart[k-1].Stop {
+					t.Fatalf("%+v, %+v: CPU runs %+v and %+v at once", processes, gm, chart[k-1], slice)
+				}
+				received[slice.PID] += slice.Stop - slice.Start
+				for tick := slice.Start; tick < slice.Stop; tick++ {
+					if running[tick] == nil {
+						running[tick] = make(map[string]bool)
+					}
+					running[tick][slice.PID] = true
+				}
+			}
+		}
+		for _, row := range res.Rows {
+			if received[row.ProcessID] != row.BurstDuration || row.Wait < 0 || row.Exit-row.Turnaround != row.ArrivalTime {
+				t.Fatalf("%+v, %+v: %+v received %d", processes, gm, row, received[row.ProcessID])
+			}
+		}
+		// at every tick, a gang with a process running has all of its unfinished ones running.
+		for tick, pids := range running {
+			for _, p := range processes {
+				if !pids[p.ProcessID] {
+					continue
+				}
+				for _, q := range processes {
+					unfinished := q.BurstDuration-progress(res, q.ProcessID, tick) > 0
+					if q.group() == p.group() && unfinished && !pids[q.ProcessID] {
+						t.Fatalf("%+v, %+v: %s runs at %d without %s", processes, gm, p.ProcessID, tick, q.ProcessID)
+					}
+				}
+			}
+		}
+	}
+	if checked < 100 {
+		t.Errorf("only %d of the random workloads fit their machines", checked)
+	}
+}
+
+// progress returns the CPU time pid has received before tick.
+func progress(res GangResult, pid string, tick int64) int64 {
+	var got int64
+	for _, chart := range res.CPUs {
+		for _, slice := range chart {
+			if slice.PID == pid && slice.Start < tick {
+				got += min(slice.Stop, tick) - slice.Start
+			}
+		}
+	}
+	return got
+}
+
+func Test_simulateGang_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		model     GangModel
+		processes []Process
+	}{
+		{name: "no CPUs", model: GangModel{Quantum: 1}, processes: gangs},
+		{name: "no quantum", model: GangModel{CPUs: 3}, processes: gangs},
+		{name: "gang larger than the machine", model: GangModel{CPUs: 2, Quantum: 1}, processes: gangs},
+		{
+			name:      "locks",
+			model:     GangModel{CPUs: 1, Quantum: 1},
+			processes: []Process{{ProcessID: "A", BurstDuration: 5, Locks: []Lock{{"R1", 0, 4}}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := SimulateGang(tt.model, tt.processes, "gang"); !errors.Is(err, ErrInvalidArgs) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package sched
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// WorkloadShape bounds the processes of a generated workload.
+type WorkloadShape struct {
+	Count       int
+	MaxArrival  int64
+	MaxBurst    int64
+	MaxPriority int64
+	MaxMemory   int64 // zero leaves memory out
+	Groups      int   // zero leaves groups out
+}
+
+func (s WorkloadShape) Validate() error {
+	if s.Count < 1 || s.MaxArrival < 0 || s.MaxBurst < 1 || s.MaxPriority < 1 || s.MaxMemory < 0 || s.Groups < 0 {
+		return fmt.Errorf("%w: workloads need at least one process, a burst and a priority", ErrInvalidArgs)
+	}
+	return nil
+}
+
+// GenerateWorkload returns random processes with arrivals in [0, maxArrival], bursts in
+// [1, maxBurst], priorities in [1, maxPriority], memory in [1, maxMemory], and spread over
+// groups G0 to G<groups-1>.
+func GenerateWorkload(r *rand.Rand, shape WorkloadShape) []Process {
+	processes := make([]Process, shape.Count)
+	for i := range processes {
+		processes[i] = Process{
+			ProcessID:     fmt.Sprintf("P%d", i),
+			ArrivalTime:   r.Int64N(shape.MaxArrival + 1),
+			BurstDuration: 1 + r.Int64N(shape.MaxBurst),
+			Priority:      1 + r.Int64N(shape.MaxPriority),
+		}
+		if shape.MaxMemory > 0 {
+			processes[i].Memory = 1 + r.Int64N(shape.MaxMemory)
+		}
+		if shape.Groups > 0 {
+			processes[i].Group = fmt.Sprintf("G%d", r.IntN(shape.Groups))
+		}
+	}
+
+	return processes
+}
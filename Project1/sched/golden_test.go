@@ -0,0 +1,95 @@
+package sched
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// update regenerates the golden files: go test -run TestGolden -update
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// TestGolden runs every workload in testdata/workloads through every algorithm with its
+// default params, comparing the text and JSON output with
+// testdata/golden/<workload>.<algorithm>.{txt,json}.
+func TestGolden(t *testing.T) {
+	t.Parallel()
+	workloads, err := filepath.Glob(filepath.Join("testdata", "workloads", "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workloads) == 0 {
+		t.Fatal("no workloads found")
+	}
+	for _, workload := range workloads {
+		workload := workload
+		name := strings.TrimSuffix(filepath.Base(workload), ".csv")
+		for _, alg := range Algorithms() {
+			alg := alg
+			t.Run(name+"/"+alg.Name, func(t *testing.T) {
+				t.Parallel()
+				processes := loadWorkload(t, workload)
+				res := Simulate(alg.Policy(alg.Defaults(), Machine{}), processes, nil).Result(alg.Title)
+
+				golden := filepath.Join("testdata", "golden", name+"."+alg.Name)
+				var text bytes.Buffer
+				OutputResult(&text, res)
+				compareGolden(t, golden+".txt", text.Bytes(), func(want []byte) string {
+					return cmp.Diff(string(want), text.String())
+				})
+
+				b, err := json.MarshalIndent(res, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				compareGolden(t, golden+".json", append(b, '\n'), func(want []byte) string {
+					var wantRes Result
+					if err := json.Unmarshal(want, &wantRes); err != nil {
+						return err.Error()
+					}
+					return cmp.Diff(wantRes, res)
+				})
+			})
+		}
+	}
+}
+
+// compareGolden writes got to the golden file when updating, otherwise reports diff's
+// comparison of the golden file with got.
+func compareGolden(t *testing.T, file string, got []byte, diff func(want []byte) string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if d := diff(want); d != "" {
+		t.Errorf("%s mismatch (-want +got):\n%s", file, d)
+	}
+}
+
+func loadWorkload(t *testing.T, file string) []Process {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	processes, err := LoadProcesses(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return processes
+}
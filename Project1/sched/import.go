@@ -0,0 +1,215 @@
+package sched
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userHZ is the kernel's USER_HZ, the unit of the CPU and start times in /proc/[pid]/stat.
+const userHZ = 100
+
+// Trace is a workload recorded on a real machine: the processes to feed the simulated schedulers,
+// and the timing the kernel actually gave them.
+type Trace struct {
+	Processes []Process
+	Recorded  []ProcessResult
+}
+
+// recording accumulates the samples of a single task.
+type recording struct {
+	id       string
+	first    int // order of first appearance
+	priority int64
+	arrival  time.Duration
+	burst    time.Duration
+	wait     time.Duration
+	exit     time.Duration
+}
+
+// importers are the recorded trace formats understood by importTrace.
+var importers = map[string]func(io.Reader) ([]*recording, error){
+	"perf":     readPerfSched,
+	"procstat": readProcStat,
+}
+
+// ImportTrace converts a recorded trace into processes, with times measured in ticks of unit.
+func ImportTrace(r io.Reader, format string, unit time.Duration) (Trace, error) {
+	read, ok := importers[format]
+	if !ok {
+		return Trace{}, fmt.Errorf("%w: unknown trace format %q", ErrInvalidArgs, format)
+	}
+	if unit <= 0 {
+		return Trace{}, fmt.Errorf("%w: time unit must be positive", ErrInvalidArgs)
+	}
+	recs, err := read(r)
+	if err != nil {
+		return Trace{}, err
+	}
+	if len(recs) == 0 {
+		return Trace{}, fmt.Errorf("%w: no tasks found in trace", ErrInvalidArgs)
+	}
+
+	slices.SortStableFunc(recs, func(a, b *recording) int {
+		if c := cmp.Compare(a.arrival, b.arrival); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.first, b.first)
+	})
+	origin := recs[0].arrival
+	ticks := func(d time.Duration) int64 {
+		return int64(math.Round(float64(d) / float64(unit)))
+	}
+
+	trace := Trace{
+		Processes: make([]Process, len(recs)),
+		Recorded:  make([]ProcessResult, len(recs)),
+	}
+	for i, rec := range recs {
+		p := Process{
+			ProcessID:     rec.id,
+			ArrivalTime:   ticks(rec.arrival - origin),
+			BurstDuration: max(ticks(rec.burst), 1),
+			Priority:      rec.priority,
+		}
+		exit := max(ticks(rec.exit-origin), p.ArrivalTime+p.BurstDuration)
+		trace.Processes[i] = p
+		trace.Recorded[i] = ProcessResult{
+			Process:    p,
+			Wait:       ticks(rec.wait),
+			Turnaround: exit - p.ArrivalTime,
+			Exit:       exit,
+		}
+	}
+
+	return trace, nil
+}
+
+// readPerfSched reads the output of `perf sched timehist`, where every line is a task being
+// switched out:
+//
+//	time    cpu  task name[tid/pid]  wait time  sch delay  run time
+//
+// with the time in seconds and the last three columns in milliseconds.
+// A task arrives when it first became runnable, its burst is its total run time, and its
+// recorded wait is its total scheduling delay.
+func readPerfSched(r io.Reader) ([]*recording, error) {
+	var (
+		recs  []*recording
+		tasks = make(map[string]*recording)
+		lines = bufio.NewScanner(r)
+		line  int
+	)
+	for lines.Scan() {
+		line++
+		fields := strings.Fields(lines.Text())
+		if len(fields) < 6 || !strings.HasPrefix(fields[1], "[") {
+			continue // header, separator or summary line.
+		}
+		at, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		name := strings.Join(fields[2:len(fields)-3], " ")
+		if strings.HasPrefix(name, "<idle>") {
+			continue
+		}
+		var ms [3]float64
+		for i, f := range fields[len(fields)-3:] {
+			if ms[i], err = strconv.ParseFloat(f, 64); err != nil {
+				return nil, fmt.Errorf("%w: line %d: parsing perf sched times", err, line)
+			}
+		}
+		stop := seconds(at)
+		schDelay, run := millis(ms[1]), millis(ms[2])
+
+		rec, ok := tasks[name]
+		if !ok {
+			rec = &recording{id: name, first: len(recs), arrival: stop - run - schDelay}
+			tasks[name] = rec
+			recs = append(recs, rec)
+		}
+		rec.burst += run
+		rec.wait += schDelay
+		rec.exit = max(rec.exit, stop)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading perf sched trace", err)
+	}
+
+	return recs, nil
+}
+
+// readProcStat reads periodic samples of /proc/[pid]/stat, one per line, each prefixed with
+// the seconds since boot it was taken at (the first field of /proc/uptime), e.g.:
+//
+//	echo "$(cut -d' ' -f1 /proc/uptime) $(cat /proc/$PID/stat)" >> samples.log
+//
+// A task arrives at its start time, its burst is the most user and system time sampled, and it
+// exits at the first sample showing that much CPU time.
+func readProcStat(r io.Reader) ([]*recording, error) {
+	var (
+		recs  []*recording
+		tasks = make(map[string]*recording)
+		lines = bufio.NewScanner(r)
+		line  int
+	)
+	for lines.Scan() {
+		line++
+		text := strings.TrimSpace(lines.Text())
+		if text == "" {
+			continue
+		}
+		// the command name is parenthesized and may itself contain spaces or parentheses.
+		open, closing := strings.IndexByte(text, '('), strings.LastIndexByte(text, ')')
+		if open < 0 || closing < open {
+			return nil, fmt.Errorf("%w: line %d: missing command name", ErrInvalidArgs, line)
+		}
+		head, comm, stat := strings.Fields(text[:open]), text[open+1:closing], strings.Fields(text[closing+1:])
+		// after the command: state(3) ... utime(14) stime(15) ... priority(18) ... starttime(22).
+		if len(head) != 2 || len(stat) < 20 {
+			return nil, fmt.Errorf("%w: line %d: expected an uptime and a stat line", ErrInvalidArgs, line)
+		}
+		uptime, err := strconv.ParseFloat(head[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: parsing uptime", err, line)
+		}
+		var values [4]int64
+		for i, field := range []string{stat[11], stat[12], stat[15], stat[19]} {
+			if values[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+				return nil, fmt.Errorf("%w: line %d: parsing stat fields", err, line)
+			}
+		}
+		at, cpu, start := seconds(uptime), clockTicks(values[0]+values[1]), clockTicks(values[3])
+
+		id := fmt.Sprintf("%s[%s]", comm, head[1])
+		rec, ok := tasks[id]
+		if !ok {
+			rec = &recording{id: id, first: len(recs), priority: values[2], arrival: start, exit: at}
+			tasks[id] = rec
+			recs = append(recs, rec)
+		}
+		if cpu > rec.burst {
+			rec.burst = cpu
+			rec.exit = at
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading /proc stat samples", err)
+	}
+	for _, rec := range recs {
+		rec.wait = max(rec.exit-rec.arrival-rec.burst, 0)
+	}
+
+	return recs, nil
+}
+
+func seconds(s float64) time.Duration  { return time.Duration(s * float64(time.Second)) }
+func millis(ms float64) time.Duration  { return time.Duration(ms * float64(time.Millisecond)) }
+func clockTicks(n int64) time.Duration { return time.Duration(n) * time.Second / userHZ }
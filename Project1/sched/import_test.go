@@ -0,0 +1,136 @@
+package sched
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_importTrace(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		r      io.Reader
+		format string
+		unit   time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Trace
+		wantErr error
+	}{
+		{
+			name: "perf sched timehist",
+			args: args{
+				r: strings.NewReader(`           time    cpu  task name                       wait time  sch delay   run time
+                        [tid/pid]                          (msec)     (msec)     (msec)
+--------------- ------  ------------------------------  ---------  ---------  ---------
+   79371.874569 [0011]  gcc[31949]                          0.000      0.000      4.148
+   79371.875000 [0011]  <idle>                              0.000      0.000      0.431
+   79371.876100 [0011]  cc1[31950]                          0.000      1.000      1.100
+   79371.880000 [0011]  gcc[31949]                          1.100      0.800      3.100
+`),
+				format: "perf",
+				unit:   time.Millisecond,
+			},
+			want: Trace{
+				Processes: []Process{
+					{ProcessID: "gcc[31949]", ArrivalTime: 0, BurstDuration: 7},
+					{ProcessID: "cc1[31950]", ArrivalTime: 4, BurstDuration: 1},
+				},
+				Recorded: []ProcessResult{
+					{
+						Process: Process{ProcessID: "gcc[31949]", ArrivalTime: 0, BurstDuration: 7},
+						Wait:    1, Turnaround: 10, Exit: 10,
+					},
+					{
+						Process: Process{ProcessID: "cc1[31950]", ArrivalTime: 4, BurstDuration: 1},
+						Wait:    1, Turnaround: 2, Exit: 6,
+					},
+				},
+			},
+		},
+		{
+			name: "proc stat samples",
+			args: args{
+				r: strings.NewReader(`100.00 42 (busy loop) R 1 42 42 0 -1 4194304 0 0 0 0 10 0 0 0 20 0 1 0 9950 0 0
+100.50 42 (busy loop) R 1 42 42 0 -1 4194304 0 0 0 0 50 10 0 0 20 0 1 0 9950 0 0
+100.50 43 (sh) S 1 43 43 0 -1 4194304 0 0 0 0 5 5 0 0 25 5 1 0 10000 0 0
+101.00 42 (busy loop) S 1 42 42 0 -1 4194304 0 0 0 0 50 10 0 0 20 0 1 0 9950 0 0
+`),
+				format: "procstat",
+				unit:   10 * time.Millisecond,
+			},
+			want: Trace{
+				Processes: []Process{
+					{ProcessID: "busy loop[42]", ArrivalTime: 0, BurstDuration: 60, Priority: 20},
+					{ProcessID: "sh[43]", ArrivalTime: 50, BurstDuration: 10, Priority: 25},
+				},
+				Recorded: []ProcessResult{
+					{
+						Process: Process{ProcessID: "busy loop[42]", ArrivalTime: 0, BurstDuration: 60, Priority: 20},
+						Wait:    40, Turnaround: 100, Exit: 100,
+					},
+					{
+						Process: Process{ProcessID: "sh[43]", ArrivalTime: 50, BurstDuration: 10, Priority: 25},
+						Wait:    40, Turnaround: 50, Exit: 100,
+					},
+				},
+			},
+		},
+		{
+			name: "unknown format",
+			args: args{
+				r:      strings.NewReader(""),
+				format: "strace",
+				unit:   time.Millisecond,
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "empty trace",
+			args: args{
+				r:      strings.NewReader(""),
+				format: "perf",
+				unit:   time.Millisecond,
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "truncated stat line",
+			args: args{
+				r:      strings.NewReader("100.00 42 (busy) R 1 42\n"),
+				format: "procstat",
+				unit:   time.Millisecond,
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "read error",
+			args: args{
+				r:      iotest.ErrReader(io.ErrUnexpectedEOF),
+				format: "perf",
+				unit:   time.Millisecond,
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ImportTrace(tt.args.r, tt.args.format, tt.args.unit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
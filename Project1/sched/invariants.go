@@ -0,0 +1,167 @@
+package sched
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+var ErrInvariant = errors.New("invariant violated")
+
+// CheckInvariants validates a schedule of processes, returning every violation found:
+// • time slices don't overlap
+// • every process receives exactly its burst of CPU, never before it arrives (when the CPU is
+// slowed down, the work done is at least the burst, wasting less than a tick)
+// • every row's timing agrees with the Gantt chart, and the averages agree with the rows
+// • time out of memory counts as admission delay, and time blocked on resources as blocked, not wait
+// • when workConserving, the CPU is never idle while a process is ready
+func CheckInvariants(processes []Process, res Result, workConserving bool) error {
+	var errs []error
+	violation := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvariant}, a...)...))
+	}
+
+	byID := make(map[string]Process, len(processes))
+	for _, p := range processes {
+		byID[p.ProcessID] = p
+	}
+	cpu := make(map[string]int64, len(processes))
+	work := make(map[string]float64, len(processes))
+	lastStop := make(map[string]int64, len(processes))
+	gantt := slices.Clone(res.Gantt)
+	slices.SortStableFunc(gantt, func(a, b TimeSlice) int { return cmp.Compare(a.Start, b.Start) })
+	for i, slice := range gantt {
+		if slice.Stop <= slice.Start {
+			violation("slice %s [%d, %d) is empty", slice.PID, slice.Start, slice.Stop)
+		}
+		if i > 0 && slice.Start < gantt[i-1].Stop {
+			violation("slice %s [%d, %d) overlaps %s [%d, %d)", slice.PID, slice.Start, slice.Stop,
+				gantt[i-1].PID, gantt[i-1].Start, gantt[i-1].Stop)
+		}
+		p, ok := byID[slice.PID]
+		if !ok {
+			violation("slice for unknown process %s", slice.PID)
+			continue
+		}
+		if slice.Start < p.ArrivalTime {
+			violation("%s runs at %d before arriving at %d", slice.PID, slice.Start, p.ArrivalTime)
+		}
+		cpu[slice.PID] += slice.Stop - slice.Start
+		speed := slice.Speed
+		if speed == 0 {
+			speed = 1
+		}
+		work[slice.PID] += float64(slice.Stop-slice.Start) * speed
+		lastStop[slice.PID] = max(lastStop[slice.PID], slice.Stop)
+	}
+
+	blocked := make(map[string]int64, len(processes))
+	for _, b := range res.Blocked {
+		if b.Stop <= b.Start {
+			violation("%s blocked on %s over empty [%d, %d)", b.PID, b.Resource, b.Start, b.Stop)
+		}
+		blocked[b.PID] += b.Stop - b.Start
+	}
+
+	if len(res.Rows) != len(processes) {
+		violation("%d rows for %d processes", len(res.Rows), len(processes))
+	}
+	var totalWait, totalTurnaround float64
+	exits := make(map[string]int64, len(res.Rows))
+	for _, row := range res.Rows {
+		p, ok := byID[row.ProcessID]
+		if !ok {
+			violation("row for unknown process %s", row.ProcessID)
+			continue
+		}
+		exits[p.ProcessID] = row.Exit
+		if got := work[p.ProcessID]; got < float64(p.BurstDuration)-1e-9 || got >= float64(p.BurstDuration+1) {
+			violation("%s received %.2f of work, want its burst of %d", p.ProcessID, got, p.BurstDuration)
+		}
+		if p.BurstDuration > 0 && row.Exit != lastStop[p.ProcessID] {
+			violation("%s exits at %d, but last runs until %d", p.ProcessID, row.Exit, lastStop[p.ProcessID])
+		}
+		if want := row.Exit - p.ArrivalTime; row.Turnaround != want {
+			violation("%s turnaround is %d, want %d", p.ProcessID, row.Turnaround, want)
+		}
+		if row.Admission < 0 {
+			violation("%s admission delay %d is negative", p.ProcessID, row.Admission)
+		}
+		if want := blocked[p.ProcessID]; row.Blocked != want {
+			violation("%s blocked for %d, want %d", p.ProcessID, row.Blocked, want)
+		}
+		if want := row.Turnaround - cpu[p.ProcessID] - row.Admission - row.Blocked; row.Wait != want {
+			violation("%s wait is %d, want %d", p.ProcessID, row.Wait, want)
+		}
+		totalWait += float64(row.Wait)
+		totalTurnaround += float64(row.Turnaround)
+	}
+	if n := float64(len(res.Rows)); n > 0 {
+		if want := totalWait / n; !closeTo(res.AverageWait, want) {
+			violation("average wait is %.4f, want %.4f", res.AverageWait, want)
+		}
+		if want := totalTurnaround / n; !closeTo(res.AverageTurnaround, want) {
+			violation("average turnaround is %.4f, want %.4f", res.AverageTurnaround, want)
+		}
+	}
+
+	if workConserving {
+		// a process is ready during an idle gap if it arrived before the gap ends, and exits
+		// after it starts: the latest exit of the processes arriving before each gap ends.
+		byArrival := slices.Clone(processes)
+		slices.SortStableFunc(byArrival, func(a, b Process) int { return cmp.Compare(a.ArrivalTime, b.ArrivalTime) })
+		latest := make([]int, len(byArrival)) // of byArrival[:i+1], the process exiting last
+		for i, p := range byArrival {
+			latest[i] = i
+			if i > 0 && !exitsAfter(exits, p, byArrival[latest[i-1]]) {
+				latest[i] = latest[i-1]
+			}
+		}
+		for _, idle := range idleGaps(processes, gantt) {
+			n, _ := slices.BinarySearchFunc(byArrival, idle.Stop, func(p Process, t int64) int { return cmp.Compare(p.ArrivalTime, t) })
+			if n == 0 {
+				continue
+			}
+			p := byArrival[latest[n-1]]
+			if exit, ok := exits[p.ProcessID]; ok && p.BurstDuration > 0 && exit > idle.Start {
+				violation("CPU idle during [%d, %d) while %s is ready", idle.Start, idle.Stop, p.ProcessID)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// exitsAfter reports whether a, if it has a burst, exits after b, or b doesn't count.
+func exitsAfter(exits map[string]int64, a, b Process) bool {
+	exitA, okA := exits[a.ProcessID]
+	exitB, okB := exits[b.ProcessID]
+	switch {
+	case !okA || a.BurstDuration == 0:
+		return false
+	case !okB || b.BurstDuration == 0:
+		return true
+	}
+	return exitA > exitB
+}
+
+// idleGaps returns the intervals between the first arrival and the last slice where the CPU is idle.
+func idleGaps(processes []Process, gantt []TimeSlice) []TimeSlice {
+	if len(processes) == 0 || len(gantt) == 0 {
+		return nil
+	}
+	last := slices.MinFunc(processes, func(a, b Process) int { return cmp.Compare(a.ArrivalTime, b.ArrivalTime) }).ArrivalTime
+	var gaps []TimeSlice
+	for _, slice := range gantt {
+		if slice.Start > last {
+			gaps = append(gaps, TimeSlice{PID: "-", Start: last, Stop: slice.Start})
+		}
+		last = max(last, slice.Stop)
+	}
+
+	return gaps
+}
+
+func closeTo(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
@@ -0,0 +1,154 @@
+package sched
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+// randomWorkload generates n processes with small arrivals, bursts and priorities, so that
+// ties, idle gaps and preemptions are all common.
+func randomWorkload(r *rand.Rand, n int) []Process {
+	return GenerateWorkload(r, WorkloadShape{Count: n, MaxArrival: int64(3 * n), MaxBurst: 8, MaxPriority: 4})
+}
+
+// Test_checkInvariants_random checks the schedules of every algorithm on random workloads,
+// of each kind the simulation models, run on a machine to match.
+func Test_checkInvariants_random(t *testing.T) {
+	t.Parallel()
+	kinds := []struct {
+		name     string
+		generate func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine)
+	}{
+		{
+			name: "plain",
+			generate: func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine) {
+				return randomWorkload(r, 1+r.IntN(12)), alg.Defaults(), Machine{}
+			},
+		},
+		{
+			name: "params",
+			generate: func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine) {
+				params := make(map[string]int64, len(alg.Params))
+				for _, p := range alg.Params {
+					most := p.Max
+					if most == 0 {
+						most = p.Min + 9
+					}
+					params[p.Name] = p.Min + r.Int64N(most-p.Min+1)
+				}
+				return randomWorkload(r, 1+r.IntN(12)), params, Machine{}
+			},
+		},
+		{
+			name: "memory",
+			generate: func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine) {
+				n := 1 + r.IntN(12)
+				processes := GenerateWorkload(r, WorkloadShape{Count: n, MaxArrival: int64(3 * n), MaxBurst: 8, MaxPriority: 4, MaxMemory: 8})
+				return processes, alg.Defaults(), Machine{Memory: 8 + r.Int64N(8), Swap: r.IntN(2) == 0}
+			},
+		},
+		{
+			name: "groups",
+			generate: func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine) {
+				n := 1 + r.IntN(12)
+				processes := GenerateWorkload(r, WorkloadShape{Count: n, MaxArrival: int64(3 * n), MaxBurst: 8, MaxPriority: 4, Groups: 3})
+				return processes, alg.Defaults(), Machine{}
+			},
+		},
+		{
+			name: "locks",
+			generate: func(r *rand.Rand, alg Algorithm) ([]Process, map[string]int64, Machine) {
+				return withLocks(r, randomWorkload(r, 1+r.IntN(12)), 1+r.IntN(3)), alg.Defaults(), Machine{}
+			},
+		},
+	}
+	for k, kind := range kinds {
+		for a, alg := range Algorithms() {
+			k, kind, a, alg := k, kind, a, alg
+			t.Run(kind.name+"/"+alg.Name, func(t *testing.T) {
+				t.Parallel()
+				r := rand.New(rand.NewPCG(4600+uint64(k), uint64(a+1)))
+				for i := 0; i < 500; i++ {
+					processes, params, m := kind.generate(r, alg)
+					res, err := Run(alg, params, m, processes)
+					if err != nil {
+						t.Fatalf("params %v, machine %+v, workload %+v: %v", params, m, processes, err)
+					}
+					if err := CheckInvariants(processes, res, true); err != nil {
+						t.Fatalf("params %v, machine %+v, workload %+v:\n%v", params, m, processes, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_checkInvariants(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: "A", ArrivalTime: 0, BurstDuration: 2},
+		{ProcessID: "B", ArrivalTime: 1, BurstDuration: 2},
+	}
+	valid := Simulate(fcfs.policy(), processes, nil).Result("valid")
+	tests := []struct {
+		name    string
+		mutate  func(res *Result)
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			mutate: func(res *Result) {},
+		},
+		{
+			name: "overlapping slices",
+			mutate: func(res *Result) {
+				res.Gantt = []TimeSlice{{PID: "A", Start: 0, Stop: 2}, {PID: "B", Start: 1, Stop: 3}}
+			},
+			wantErr: ErrInvariant,
+		},
+		{
+			name: "short burst",
+			mutate: func(res *Result) {
+				res.Gantt = []TimeSlice{{PID: "A", Start: 0, Stop: 1}, {PID: "B", Start: 2, Stop: 4}}
+			},
+			wantErr: ErrInvariant,
+		},
+		{
+			name: "runs before arrival",
+			mutate: func(res *Result) {
+				res.Gantt = []TimeSlice{{PID: "B", Start: 0, Stop: 2}, {PID: "A", Start: 2, Stop: 4}}
+			},
+			wantErr: ErrInvariant,
+		},
+		{
+			name: "idle while ready",
+			mutate: func(res *Result) {
+				res.Gantt = []TimeSlice{{PID: "A", Start: 0, Stop: 2}, {PID: "B", Start: 3, Stop: 5}}
+				res.Rows[1].Exit, res.Rows[1].Turnaround, res.Rows[1].Wait = 5, 4, 2
+				res.AverageWait, res.AverageTurnaround = 1, 3
+			},
+			wantErr: ErrInvariant,
+		},
+		{
+			name: "average mismatch",
+			mutate: func(res *Result) {
+				res.AverageWait++
+			},
+			wantErr: ErrInvariant,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := valid
+			res.Gantt = append([]TimeSlice(nil), valid.Gantt...)
+			res.Rows = append([]ProcessResult(nil), valid.Rows...)
+			tt.mutate(&res)
+			if err := CheckInvariants(processes, res, true); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkInvariants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
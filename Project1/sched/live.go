@@ -0,0 +1,304 @@
+package sched
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type (
+	// runner runs the processes dispatch schedules, each only while it's granted the CPU.
+	runner interface {
+		// arrive readies the process of t to run, as it arrives.
+		arrive(t *task) error
+		// resume grants the process of t the CPU, until it's paused or finishes.
+		resume(t *task)
+		// pause takes the CPU back from the process of t, reporting whether it finished first.
+		pause(t *task) bool
+		// exits delivers the task of every process that finishes.
+		exits() <-chan *task
+		// stop ends every process that hasn't finished.
+		stop()
+	}
+
+	// workers run processes as goroutines doing busy work for their bursts.
+	workers struct {
+		tick    time.Duration
+		grants  []chan grant // by task index, of the workers that have started
+		preempt chan struct{}
+		paused  chan struct{}
+		done    chan *task
+	}
+
+	// grant gives a worker the CPU until preempt is closed.
+	grant struct {
+		preempt <-chan struct{}
+	}
+
+	// LiveSlice is a time slice measured in wall-clock time since the first slice started.
+	LiveSlice struct {
+		PID   string
+		Start time.Duration
+		Stop  time.Duration
+	}
+
+	// LiveRow is the measured timing of a single process, in ticks.
+	LiveRow struct {
+		ProcessID     string
+		SimulatedExit int64
+		Exit          float64
+		Wait          float64
+		Turnaround    float64
+	}
+
+	// LiveResult is a schedule run for real, next to the simulated schedule it followed.
+	LiveResult struct {
+		Slices            []LiveSlice
+		Rows              []LiveRow
+		AverageWait       float64
+		AverageTurnaround float64
+		Timescale         // of the simulated schedule
+	}
+)
+
+// RunLive runs the processes of the simulation sim on real goroutines, with one tick lasting
+// tick, to compare with the simulated schedule. Each process is a goroutine doing busy work for
+// its burst, which is dispatched under sim's policy (see dispatch).
+// Memory, resources and frequency scaling aren't modelled.
+func RunLive(sim *Simulation, tick time.Duration) LiveResult {
+	// starting a worker can't fail.
+	res, _ := dispatch(sim, tick, &workers{
+		tick:   tick,
+		grants: make([]chan grant, len(sim.tasks)),
+		paused: make(chan struct{}),
+		done:   make(chan *task),
+	})
+	return res
+}
+
+func (ws *workers) arrive(t *task) error {
+	ws.grants[t.index] = make(chan grant)
+	go work(t, time.Duration(t.BurstDuration)*ws.tick, ws.grants[t.index], ws.paused, ws.done)
+	return nil
+}
+
+func (ws *workers) resume(t *task) {
+	ws.preempt = make(chan struct{})
+	ws.grants[t.index] <- grant{preempt: ws.preempt}
+}
+
+func (ws *workers) pause(*task) bool {
+	close(ws.preempt)
+	select {
+	case <-ws.paused:
+		return false
+	case <-ws.done:
+		return true
+	}
+}
+
+func (ws *workers) exits() <-chan *task { return ws.done }
+
+func (ws *workers) stop() {
+	for _, grants := range ws.grants {
+		if grants != nil {
+			close(grants)
+		}
+	}
+}
+
+// work stands in for the process of t: it busy-works whenever it is granted the CPU, until its
+// burst has been received, reporting back every time it stops.
+func work(t *task, burst time.Duration, grants <-chan grant, paused chan<- struct{}, done chan<- *task) {
+	var used time.Duration
+	for g := range grants {
+		start := time.Now()
+		preempted := false
+		for !preempted && used+time.Since(start) < burst {
+			select {
+			case <-g.preempt:
+				preempted = true
+			default:
+				spin()
+				// yield, so the dispatcher can preempt promptly even when it shares a CPU.
+				runtime.Gosched()
+			}
+		}
+		used += time.Since(start)
+		if used >= burst {
+			done <- t
+			return
+		}
+		paused <- struct{}{}
+	}
+}
+
+// spin burns a little CPU.
+func spin() {
+	x := 1
+	for i := 0; i < 1000; i++ {
+		x = x*31 + i
+	}
+	_ = x
+}
+
+// dispatch schedules the processes of the simulation sim on the wall clock, with one tick
+// lasting tick, running them on r under sim's policy: processes join the ready queue when they
+// arrive by the wall clock, and are ordered by the CPU time they've been measured to receive,
+// so a late or slow run can be scheduled differently.
+func dispatch(sim *Simulation, tick time.Duration, r runner) (res LiveResult, err error) {
+	res.Timescale = sim.policy.clock
+	type process struct {
+		*task
+		ran  time.Duration // wall-clock time spent running
+		exit time.Duration
+		done bool
+	}
+	var (
+		p         = sim.policy
+		ready     = newReadyQueue(p)
+		processes = make(map[*task]*process, len(sim.tasks))
+		arrivals  = make([]*process, len(sim.arrivals))
+		byIndex   = make([]*process, len(sim.tasks))
+		next      int
+		finished  int
+		seq       uint64
+		running   *process
+		since     time.Duration // when running was granted the CPU
+		expires   time.Duration // when running's quantum expires
+	)
+	for i, t := range sim.arrivals {
+		c := &process{task: p.newTask(t.index, t.Process)}
+		arrivals[i], byIndex[t.index], processes[c.task] = c, c, c
+	}
+	defer r.stop()
+
+	// the run starts with the first arrival.
+	var first int64
+	if len(arrivals) > 0 {
+		first = arrivals[0].ArrivalTime
+	}
+	at := func(ticks int64) time.Duration { return time.Duration(ticks-first) * tick }
+	origin := time.Now()
+	enqueue := func(c *process) {
+		c.seq = seq
+		seq++
+		ready.push(c.task)
+	}
+	finish := func(c *process, now time.Duration) {
+		c.done, c.exit = true, now
+		finished++
+	}
+	// stop ends the running process's slice, requeueing it unless it's done.
+	stop := func(done bool) {
+		c, now := running, time.Since(origin)
+		running = nil
+		c.ran += now - since
+		c.remaining = max(c.BurstDuration-int64(c.ran/tick), 0)
+		res.Slices = append(res.Slices, LiveSlice{PID: c.ProcessID, Start: since, Stop: now})
+		if done {
+			finish(c, now)
+			return
+		}
+		enqueue(c)
+	}
+
+	for finished < len(arrivals) {
+		now := time.Since(origin)
+		for next < len(arrivals) && at(arrivals[next].ArrivalTime) <= now {
+			c := arrivals[next]
+			next++
+			if err := r.arrive(c.task); err != nil {
+				return res, fmt.Errorf("%w: starting %s", err, c.ProcessID)
+			}
+			enqueue(c)
+		}
+		if running != nil {
+			// compare the ready queue with what the running process has received so far.
+			running.remaining = max(running.BurstDuration-int64((running.ran+now-since)/tick), 0)
+			expired := p.quantum > 0 && now >= expires
+			switch {
+			case ready.Len() > 0 && (expired || p.preemptive && p.compare(ready.peek(), running.task) < 0):
+				stop(r.pause(running.task))
+			case expired:
+				// nothing else to run, so the quantum is simply renewed.
+				expires = now + time.Duration(p.quantum)*tick
+			}
+		}
+		for running == nil && ready.Len() > 0 {
+			c := processes[ready.pop()]
+			if c.done {
+				continue // it finished as it was paused
+			}
+			running = c
+			since, expires = time.Since(origin), now+time.Duration(p.quantum)*tick
+			r.resume(c.task)
+		}
+
+		// sleep until something happens.
+		wake := time.Duration(-1)
+		if next < len(arrivals) {
+			wake = at(arrivals[next].ArrivalTime)
+		}
+		if running != nil && p.quantum > 0 && (wake < 0 || expires < wake) {
+			wake = expires
+		}
+		var timeout <-chan time.Time
+		if wake >= 0 {
+			timeout = time.After(wake - now)
+		}
+		select {
+		case t := <-r.exits():
+			if c := processes[t]; c == running {
+				stop(true)
+			} else {
+				finish(c, time.Since(origin))
+			}
+		case <-timeout:
+		}
+	}
+
+	ticks := func(d time.Duration) float64 { return float64(d) / float64(tick) }
+	for _, t := range sim.tasks {
+		c := byIndex[t.index]
+		exit := float64(first) + ticks(c.exit)
+		turnaround := exit - float64(t.ArrivalTime)
+		row := LiveRow{
+			ProcessID:     t.ProcessID,
+			SimulatedExit: t.exit,
+			Exit:          exit,
+			Wait:          turnaround - ticks(c.ran),
+			Turnaround:    turnaround,
+		}
+		res.Rows = append(res.Rows, row)
+		res.AverageWait += row.Wait / float64(len(sim.tasks))
+		res.AverageTurnaround += row.Turnaround / float64(len(sim.tasks))
+	}
+
+	return res, nil
+}
+
+// OutputLive writes each process's measured times in res beside the simulated ones, under title.
+func OutputLive(w io.Writer, title string, res LiveResult) {
+	_, _ = fmt.Fprintln(w, title)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Simulated exit", "Measured exit", "Drift", "Wait", "Turnaround"})
+	ts := res.Timescale
+	for _, r := range res.Rows {
+		table.Append([]string{
+			r.ProcessID,
+			ts.FormatTime(r.SimulatedExit),
+			ts.FormatMean(r.Exit),
+			ts.FormatChange(r.Exit - float64(r.SimulatedExit)),
+			ts.FormatMean(r.Wait),
+			ts.FormatMean(r.Turnaround),
+		})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "Measured average wait: %s%s\n", ts.FormatMean(res.AverageWait), ts.unitSuffix())
+	_, _ = fmt.Fprintf(w, "Measured average turnaround: %s%s\n", ts.FormatMean(res.AverageTurnaround), ts.unitSuffix())
+}
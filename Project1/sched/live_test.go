@@ -0,0 +1,105 @@
+package sched
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// Test_runLive only checks what holds however busy the machine running it is: wall-clock
+// measurements drift, so they can't be compared exactly with the simulation, and the
+// dispatcher may schedule the drifted run differently.
+func Test_runLive(t *testing.T) {
+	const tick = 2 * time.Millisecond
+	for _, alg := range Algorithms() {
+		alg := alg
+		t.Run(alg.Name, func(t *testing.T) {
+			sim := Simulate(alg.Policy(alg.Defaults(), Machine{}), exampleProcesses, nil)
+			res := RunLive(sim, tick)
+
+			if len(res.Slices) < len(exampleProcesses) {
+				t.Errorf("ran %d slices, want at least one per process", len(res.Slices))
+			}
+			received := make(map[string]time.Duration)
+			for i, slice := range res.Slices {
+				if slice.Stop < slice.Start || i > 0 && slice.Start < res.Slices[i-1].Stop {
+					t.Errorf("slice %+v overlaps or ends before it starts", slice)
+				}
+				received[slice.PID] += slice.Stop - slice.Start
+			}
+			if len(res.Rows) != len(exampleProcesses) {
+				t.Fatalf("got %d rows, want %d", len(res.Rows), len(exampleProcesses))
+			}
+			for i, r := range res.Rows {
+				p := exampleProcesses[i]
+				if r.ProcessID != p.ProcessID || r.Exit <= float64(p.ArrivalTime) {
+					t.Errorf("row %+v doesn't finish after %s arrives at %d", r, p.ProcessID, p.ArrivalTime)
+				}
+				if got := received[p.ProcessID]; got < time.Duration(p.BurstDuration)*tick {
+					t.Errorf("%s received %v, want its whole burst of %d ticks", p.ProcessID, got, p.BurstDuration)
+				}
+				if r.Wait < 0 || r.Wait > r.Turnaround {
+					t.Errorf("%s measured wait %.2f, turnaround %.2f", r.ProcessID, r.Wait, r.Turnaround)
+				}
+			}
+		})
+	}
+}
+
+// Test_runLive_policy checks that the dispatcher picks processes by the policy, from what
+// has arrived and how much CPU time it has received.
+func Test_runLive_policy(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler scheduler
+		processes []Process
+		wantFirst []string
+	}{
+		{
+			name:      "sjf runs the shortest first",
+			scheduler: sjf,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 6},
+				{ProcessID: "B", BurstDuration: 2},
+				{ProcessID: "C", BurstDuration: 4},
+			},
+			wantFirst: []string{"B", "C", "A"},
+		},
+		{
+			name:      "sjfp runs the highest priority first",
+			scheduler: sjfp,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2, Priority: 2},
+				{ProcessID: "B", BurstDuration: 4, Priority: 1},
+				{ProcessID: "C", BurstDuration: 1, Priority: 3},
+			},
+			wantFirst: []string{"B", "A", "C"},
+		},
+		{
+			name:      "fcfs waits for arrivals",
+			scheduler: fcfs,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 1, ArrivalTime: 20},
+				{ProcessID: "B", BurstDuration: 1},
+			},
+			wantFirst: []string{"B", "A"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			res := RunLive(Simulate(tt.scheduler.policy(), tt.processes, nil), 2*time.Millisecond)
+			var first []string
+			seen := make(map[string]bool)
+			for _, slice := range res.Slices {
+				if !seen[slice.PID] {
+					seen[slice.PID] = true
+					first = append(first, slice.PID)
+				}
+			}
+			if !slices.Equal(first, tt.wantFirst) {
+				t.Errorf("first ran %v, want %v", first, tt.wantFirst)
+			}
+		})
+	}
+}
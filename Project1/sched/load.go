@@ -0,0 +1,192 @@
+package sched
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//region Loading processes.
+
+var ErrInvalidArgs = errors.New("invalid args")
+
+// LoadProcesses reads processes from CSV with a header row, and the columns
+// <ProcessID>,<Burst Duration>,<Arrival Time>[,<Priority>] followed by any optional columns,
+// which are found by name: Command, Memory, Group, Locks (see ParseLocks), and for threads
+// Process and IO (see ParseIO).
+func LoadProcesses(r io.Reader) ([]Process, error) {
+	return LoadProcessesScaled(r, Timescale{})
+}
+
+// LoadProcessesScaled reads processes like LoadProcesses, with burst durations and arrival
+// times written as decimal times of ts.
+func LoadProcessesScaled(r io.Reader, ts Timescale) ([]Process, error) {
+	workload, err := LoadWorkload(r, ts)
+	return workload.Processes, err
+}
+
+// LoadWorkload reads processes like LoadProcessesScaled, along with the results the file
+// expects of algorithms, on lines starting #expect (see ParseExpectation). Any other line
+// starting with # is a comment.
+func LoadWorkload(r io.Reader, ts Timescale) (Workload, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Workload{}, fmt.Errorf("%w: reading CSV", err)
+	}
+	var workload Workload
+	for i, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "#expect"); ok {
+			e, err := ParseExpectation(rest, ts)
+			if err != nil {
+				return Workload{}, fmt.Errorf("%w: line %d", err, i+1)
+			}
+			e.Line = i + 1
+			workload.Expectations = append(workload.Expectations, e)
+		}
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comment = '#'
+	var (
+		rows  [][]string
+		lines []int
+	)
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Workload{}, fmt.Errorf("%w: reading CSV", err)
+		}
+		line, _ := cr.FieldPos(0)
+		rows, lines = append(rows, row), append(lines, line)
+	}
+	if len(rows) == 0 || len(rows[0]) < 3 {
+		return Workload{}, fmt.Errorf("%w: CSV needs a header row with at least ID, burst and arrival columns", ErrInvalidArgs)
+	}
+	header := rows[0]
+	rows, lines = rows[1:], lines[1:] // skip header row
+	processes := make([]Process, len(rows))
+	seen := make(map[string]int, len(rows)) // process ID to its line
+	for i := range rows {
+		number := func(j int) (int64, error) {
+			n, err := strconv.ParseInt(strings.TrimSpace(rows[i][j]), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("%w: line %d, %s: %q isn't an integer", ErrInvalidArgs, lines[i], header[j], rows[i][j])
+			}
+			return n, nil
+		}
+		time := func(j int) (int64, error) {
+			if ts.Decimals == 0 {
+				return number(j)
+			}
+			t, err := ts.ParseTime(rows[i][j])
+			if err != nil {
+				return 0, fmt.Errorf("%w: line %d, %s: %q isn't a time with at most %d decimal places",
+					ErrInvalidArgs, lines[i], header[j], rows[i][j], ts.Decimals)
+			}
+			return t, nil
+		}
+		processes[i].ProcessID = rows[i][0]
+		if line, ok := seen[rows[i][0]]; ok {
+			return Workload{}, fmt.Errorf("%w: line %d, %s: %q is already the ID of line %d", ErrInvalidArgs, lines[i], header[0], rows[i][0], line)
+		}
+		seen[rows[i][0]] = lines[i]
+		if processes[i].BurstDuration, err = time(1); err != nil {
+			return Workload{}, err
+		}
+		if processes[i].BurstDuration < 0 {
+			return Workload{}, fmt.Errorf("%w: line %d, %s: %q is negative", ErrInvalidArgs, lines[i], header[1], rows[i][1])
+		}
+		if processes[i].ArrivalTime, err = time(2); err != nil {
+			return Workload{}, err
+		}
+		if len(rows[i]) >= 4 {
+			if processes[i].Priority, err = number(3); err != nil {
+				return Workload{}, err
+			}
+		}
+		// any further columns are optional, and found by name.
+		for j := 4; j < len(rows[i]); j++ {
+			switch strings.ToLower(strings.TrimSpace(header[j])) {
+			case "command":
+				processes[i].Command = rows[i][j]
+			case "memory":
+				if processes[i].Memory, err = number(j); err != nil {
+					return Workload{}, err
+				}
+			case "group":
+				processes[i].Group = rows[i][j]
+			case "locks":
+				if processes[i].Locks, err = ParseLocks(rows[i][j], ts); err != nil {
+					return Workload{}, fmt.Errorf("%w: line %d, %s", err, lines[i], header[j])
+				}
+			case "process":
+				processes[i].Parent = rows[i][j]
+			case "io":
+				if processes[i].IO, err = ParseIO(rows[i][j], ts); err != nil {
+					return Workload{}, fmt.Errorf("%w: line %d, %s", err, lines[i], header[j])
+				}
+			}
+		}
+	}
+
+	workload.Processes = processes
+
+	return workload, nil
+}
+
+// WriteProcesses writes processes as CSV in the format read by LoadProcesses.
+// Optional columns are only written when some process uses them.
+func WriteProcesses(w io.Writer, processes []Process) error {
+	header := []string{"ProcessID", "Burst Duration", "Arrival Time", "Priority"}
+	var optional []func(p Process) string
+	if slices.ContainsFunc(processes, func(p Process) bool { return p.Group != "" }) {
+		header = append(header, "Group")
+		optional = append(optional, func(p Process) string { return p.Group })
+	}
+	if slices.ContainsFunc(processes, func(p Process) bool { return p.Memory != 0 }) {
+		header = append(header, "Memory")
+		optional = append(optional, func(p Process) string { return strconv.FormatInt(p.Memory, 10) })
+	}
+	if slices.ContainsFunc(processes, func(p Process) bool { return len(p.Locks) > 0 }) {
+		header = append(header, "Locks")
+		optional = append(optional, func(p Process) string { return FormatLocks(p.Locks, Timescale{}) })
+	}
+	if slices.ContainsFunc(processes, func(p Process) bool { return p.Parent != "" }) {
+		header = append(header, "Process")
+		optional = append(optional, func(p Process) string { return p.Parent })
+	}
+	if slices.ContainsFunc(processes, func(p Process) bool { return len(p.IO) > 0 }) {
+		header = append(header, "IO")
+		optional = append(optional, func(p Process) string { return FormatIO(p.IO, Timescale{}) })
+	}
+	if slices.ContainsFunc(processes, func(p Process) bool { return p.Command != "" }) {
+		header = append(header, "Command")
+		optional = append(optional, func(p Process) string { return p.Command })
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	for _, p := range processes {
+		row := []string{
+			p.ProcessID,
+			strconv.FormatInt(p.BurstDuration, 10),
+			strconv.FormatInt(p.ArrivalTime, 10),
+			strconv.FormatInt(p.Priority, 10),
+		}
+		for _, column := range optional {
+			row = append(row, column(p))
+		}
+		_ = cw.Write(row)
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+//endregion
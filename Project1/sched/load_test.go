@@ -0,0 +1,214 @@
+package sched
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_loadProcesses(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		r  io.Reader
+		ts Timescale
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []Process
+		wantErr error
+	}{
+		{
+			name: "bad CSV",
+			args: args{
+				r: iotest.ErrReader(io.ErrUnexpectedEOF),
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "not an integer",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,five,0,2`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "decimal times",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,2.5,0.125,1
+P1,3,.5,2`),
+				ts: Timescale{Decimals: 3},
+			},
+			want: []Process{
+				{ProcessID: "P0", ArrivalTime: 125, BurstDuration: 2500, Priority: 1},
+				{ProcessID: "P1", ArrivalTime: 500, BurstDuration: 3000, Priority: 2},
+			},
+		},
+		{
+			name: "more precise than a tick",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,2.5,0,1`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "locks",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority,Locks
+P0,5,0,2,R1:0-4;R2:1-3
+P1,3,1,1,`),
+			},
+			want: []Process{
+				{ProcessID: "P0", BurstDuration: 5, Priority: 2, Locks: []Lock{{"R1", 0, 4}, {"R2", 1, 3}}},
+				{ProcessID: "P1", ArrivalTime: 1, BurstDuration: 3, Priority: 1},
+			},
+		},
+		{
+			name: "bad locks",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority,Locks
+P0,5,0,2,R1`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "threads",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority,Process,IO
+A1,5,0,2,A,1:3;4:2
+A2,3,1,1,A,`),
+			},
+			want: []Process{
+				{ProcessID: "A1", BurstDuration: 5, Priority: 2, Parent: "A", IO: []IOBurst{{1, 3}, {4, 2}}},
+				{ProcessID: "A2", ArrivalTime: 1, BurstDuration: 3, Priority: 1, Parent: "A"},
+			},
+		},
+		{
+			name: "bad IO",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority,IO
+A1,5,0,2,1`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "comments and expectations",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+# a comment
+P0,5,0,2
+#expect fcfs wait=0`),
+			},
+			want: []Process{{ProcessID: "P0", BurstDuration: 5, Priority: 2}},
+		},
+		{
+			name: "negative burst",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+1,-3,0,1`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "duplicate IDs",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,5,0,2
+P0,9,3,1`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "bad expectation",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,5,0,2
+#expect lottery`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "missing columns",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration`),
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "success",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority
+P0,5,0,2
+P1,9,3,1
+P2,6,3,3`),
+			},
+			want: []Process{
+				{
+					ProcessID:     "P0",
+					ArrivalTime:   0,
+					BurstDuration: 5,
+					Priority:      2,
+				},
+				{
+					ProcessID:     "P1",
+					ArrivalTime:   3,
+					BurstDuration: 9,
+					Priority:      1,
+				},
+				{
+					ProcessID:     "P2",
+					ArrivalTime:   3,
+					BurstDuration: 6,
+					Priority:      3,
+				},
+			},
+		},
+		{
+			name: "optional columns by name",
+			args: args{
+				r: strings.NewReader(`ProcessID,Burst Duration,Arrival Time,Priority,Command,Memory,Group
+P0,5,0,2,sleep 1,64,web
+P1,9,3,1,,128,db`),
+			},
+			want: []Process{
+				{
+					ProcessID:     "P0",
+					ArrivalTime:   0,
+					BurstDuration: 5,
+					Priority:      2,
+					Group:         "web",
+					Memory:        64,
+					Command:       "sleep 1",
+				},
+				{
+					ProcessID:     "P1",
+					ArrivalTime:   3,
+					BurstDuration: 9,
+					Priority:      1,
+					Group:         "db",
+					Memory:        128,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := LoadProcessesScaled(tt.args.r, tt.args.ts)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf(diff)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
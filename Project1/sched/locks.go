@@ -0,0 +1,363 @@
+package sched
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+type (
+	// Lock is a critical section of a process, holding a resource from one offset into its burst
+	// until a later one, both in CPU time received.
+	Lock struct {
+		Resource string `json:"resource"`
+		Acquire  int64  `json:"acquire"`
+		Release  int64  `json:"release"`
+	}
+
+	// LockWait is an interval a process spent blocked on a resource held by another.
+	LockWait struct {
+		PID      string `json:"pid"`
+		Resource string `json:"resource"`
+		Start    int64  `json:"start"`
+		Stop     int64  `json:"stop"`
+	}
+
+	// Inversion is an interval a process ran while a process of higher priority waited, either
+	// blocked on a resource or ready while the running process's priority was raised.
+	Inversion struct {
+		PID     string `json:"pid"`
+		Waiting string `json:"waiting"` // the highest priority waiting process
+		Start   int64  `json:"start"`
+		Stop    int64  `json:"stop"`
+	}
+
+	// lockProtocol is how the priority of a process holding a resource is raised.
+	lockProtocol int
+
+	// resource is a mutex shared by the processes with a Lock on it.
+	resource struct {
+		name    string
+		holder  *task
+		waiters []*task // blocked on it, in the order they blocked
+		ceiling int64   // highest priority of the processes using it
+	}
+
+	// lockOp is a lock of a task being acquired or released at an offset into its burst.
+	lockOp struct {
+		offset  int64
+		lock    int // index into Locks
+		release bool
+	}
+)
+
+const (
+	noProtocol lockProtocol = iota
+	// inheritPriority runs a holder at the highest priority of the processes it blocks.
+	inheritPriority
+	// priorityCeiling runs a holder at the highest priority of any process using its resources
+	// (the immediate ceiling protocol).
+	priorityCeiling
+)
+
+// ParseLocks parses critical sections written as <resource>:<acquire>-<release>, separated by
+// semicolons, with offsets written as decimal times of ts.
+func ParseLocks(spec string, ts Timescale) ([]Lock, error) {
+	var locks []Lock
+	for _, entry := range strings.Split(spec, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, span, ok := strings.Cut(entry, ":")
+		acquire, release, ok2 := strings.Cut(span, "-")
+		if !ok || !ok2 || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%w: lock %q isn't <resource>:<acquire>-<release>", ErrInvalidArgs, entry)
+		}
+		l := Lock{Resource: strings.TrimSpace(name)}
+		var err error
+		if l.Acquire, err = ts.ParseTime(acquire); err != nil {
+			return nil, fmt.Errorf("%w: lock %q", err, entry)
+		}
+		if l.Release, err = ts.ParseTime(release); err != nil {
+			return nil, fmt.Errorf("%w: lock %q", err, entry)
+		}
+		locks = append(locks, l)
+	}
+	return locks, nil
+}
+
+// FormatLocks writes locks in the format read by ParseLocks.
+func FormatLocks(locks []Lock, ts Timescale) string {
+	entries := make([]string, len(locks))
+	for i, l := range locks {
+		entries[i] = fmt.Sprintf("%s:%s-%s", l.Resource, ts.FormatTime(l.Acquire), ts.FormatTime(l.Release))
+	}
+	return strings.Join(entries, ";")
+}
+
+// validateLocks checks every critical section lies within its process's burst, that no process
+// holds a resource twice at once, and that resources are always acquired in the same order,
+// so that no set of processes can deadlock.
+func validateLocks(processes []Process) error {
+	after := make(map[string]map[string]bool) // resources acquired while holding another
+	for _, p := range processes {
+		ops := lockOps(p.Locks)
+		for i, l := range p.Locks {
+			if l.Resource == "" || l.Acquire < 0 || l.Acquire >= l.Release || l.Release > p.BurstDuration {
+				return fmt.Errorf("%w: process %s locks %q over [%d, %d), which isn't within its burst of %d",
+					ErrInvalidArgs, p.ProcessID, l.Resource, l.Acquire, l.Release, p.BurstDuration)
+			}
+			for j, other := range p.Locks {
+				if i != j && other.Resource == l.Resource && other.Acquire < l.Release && l.Acquire < other.Release {
+					return fmt.Errorf("%w: process %s locks %q twice at once", ErrInvalidArgs, p.ProcessID, l.Resource)
+				}
+			}
+		}
+		held := make(map[string]bool)
+		for _, op := range ops {
+			r := p.Locks[op.lock].Resource
+			if op.release {
+				delete(held, r)
+				continue
+			}
+			for h := range held {
+				if after[h] == nil {
+					after[h] = make(map[string]bool)
+				}
+				after[h][r] = true
+			}
+			held[r] = true
+		}
+	}
+
+	// a cycle of resources each acquired while holding the one before can deadlock.
+	state := make(map[string]int) // 1 while visiting, 2 when done
+	var visit func(r string, path []string) error
+	visit = func(r string, path []string) error {
+		switch state[r] {
+		case 1:
+			return fmt.Errorf("%w: resources can deadlock, being acquired in the order %s",
+				ErrInvalidArgs, strings.Join(append(path, r), " → "))
+		case 2:
+			return nil
+		}
+		state[r] = 1
+		next := make([]string, 0, len(after[r]))
+		for n := range after[r] {
+			next = append(next, n)
+		}
+		slices.Sort(next)
+		for _, n := range next {
+			if err := visit(n, append(path, r)); err != nil {
+				return err
+			}
+		}
+		state[r] = 2
+		return nil
+	}
+	roots := make([]string, 0, len(after))
+	for r := range after {
+		roots = append(roots, r)
+	}
+	slices.Sort(roots)
+	for _, r := range roots {
+		if err := visit(r, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockOps returns the lock operations of a burst in the order they are performed, releases
+// before acquires at the same offset.
+func lockOps(locks []Lock) []lockOp {
+	ops := make([]lockOp, 0, 2*len(locks))
+	for i, l := range locks {
+		ops = append(ops, lockOp{offset: l.Acquire, lock: i}, lockOp{offset: l.Release, lock: i, release: true})
+	}
+	slices.SortStableFunc(ops, func(a, b lockOp) int {
+		if c := cmp.Compare(a.offset, b.offset); c != 0 {
+			return c
+		}
+		switch {
+		case a.release && !b.release:
+			return -1
+		case !a.release && b.release:
+			return 1
+		}
+		return 0
+	})
+	return ops
+}
+
+// usesPriority reports whether the policy orders the ready queue by priority, so that it can
+// suffer priority inversions.
+func (p Policy) usesPriority() bool {
+	return slices.ContainsFunc(p.keys, func(k sortKey) bool { return k.name == byPriority.name })
+}
+
+// resourceFor returns the resource called name, creating it.
+func (s *Simulation) resourceFor(name string) *resource {
+	i := slices.IndexFunc(s.resources, func(r *resource) bool { return r.name == name })
+	if i < 0 {
+		s.resources = append(s.resources, &resource{name: name, ceiling: math.MaxInt64})
+		i = len(s.resources) - 1
+	}
+	return s.resources[i]
+}
+
+// lock performs the lock operations of the running task t that are due by the CPU time it has
+// received, returning false if it blocked on a resource held by another task.
+func (s *Simulation) lock(t *task) bool {
+	done := t.BurstDuration - t.remaining
+	for t.nextOp < len(t.ops) && t.ops[t.nextOp].offset <= done {
+		op := t.ops[t.nextOp]
+		r := s.resourceFor(t.Locks[op.lock].Resource)
+		switch {
+		case op.release:
+			s.unlock(t, r)
+		case r.holder != nil:
+			s.block(t, r)
+			return false
+		default:
+			r.holder = t
+			s.reprioritize(t)
+			s.emit(EventLock, t, "acquired "+r.name)
+		}
+		t.nextOp++
+	}
+	return true
+}
+
+// unlockAll releases every resource still held by t, which has finished its burst.
+func (s *Simulation) unlockAll(t *task) {
+	t.nextOp = len(t.ops)
+	for _, r := range s.resources {
+		if r.holder == t {
+			s.unlock(t, r)
+		}
+	}
+}
+
+// holds reports whether t holds any resource.
+func (s *Simulation) holds(t *task) bool {
+	return slices.ContainsFunc(s.resources, func(r *resource) bool { return r.holder == t })
+}
+
+// untilLock returns the ticks the running task t needs to run at the current speed before its
+// next lock operation, or false if it has none left.
+func (s *Simulation) untilLock(t *task) (int64, bool) {
+	if t.nextOp == len(t.ops) {
+		return 0, false
+	}
+	cycles := (t.ops[t.nextOp].offset-t.BurstDuration)*s.fullSpeed() + s.cycles(t)
+	return max((cycles+s.level.speed-1)/s.level.speed, 0), true
+}
+
+// block stops the running task t until r is handed to it, raising the priority of the tasks
+// blocking it, transitively.
+func (s *Simulation) block(t *task, r *resource) {
+	s.running = nil
+	t.blockedOn, t.blockedSince = r, s.now
+	r.waiters = append(r.waiters, t)
+	s.emit(EventBlock, t, fmt.Sprintf("%s held by %s", r.name, r.holder.ProcessID))
+	for h := r.holder; h != nil; {
+		before := h.prio
+		s.reprioritize(h)
+		if h.prio < before {
+			s.emit(EventInherit, h, fmt.Sprintf("inherited priority %d from %s", h.prio, t.ProcessID))
+		}
+		if h.blockedOn == nil {
+			break
+		}
+		h = h.blockedOn.holder
+	}
+}
+
+// unlock releases r, handing it to the best of the tasks blocked on it, which becomes ready.
+func (s *Simulation) unlock(t *task, r *resource) {
+	r.holder = nil
+	before := t.prio
+	s.reprioritize(t)
+	reason := "released " + r.name
+	if t.prio != before {
+		reason += fmt.Sprintf(", back to priority %d", t.prio)
+	}
+	s.emit(EventUnlock, t, reason)
+	if len(r.waiters) == 0 {
+		return
+	}
+	best := 0
+	for i, w := range r.waiters {
+		if s.policy.compare(w, r.waiters[best]) < 0 {
+			best = i
+		}
+	}
+	w := r.waiters[best]
+	r.waiters = slices.Delete(r.waiters, best, best+1)
+	r.holder = w
+	w.blockedOn = nil
+	w.nextOp++
+	w.blocked += s.now - w.blockedSince
+	if s.now > w.blockedSince {
+		s.lockWaits = append(s.lockWaits, LockWait{PID: w.ProcessID, Resource: r.name, Start: w.blockedSince, Stop: s.now})
+	}
+	s.reprioritize(w)
+	s.emit(EventLock, w, "acquired "+r.name+" from "+t.ProcessID)
+	s.enqueue(w, "acquired "+r.name)
+}
+
+// reprioritize sets the priority t runs at under the policy's locking protocol, from the
+// resources it holds. Queued tasks are reordered, as t may be among them.
+func (s *Simulation) reprioritize(t *task) {
+	prio := t.Priority
+	for _, r := range s.resources {
+		if r.holder != t {
+			continue
+		}
+		switch s.policy.protocol {
+		case inheritPriority:
+			for _, w := range r.waiters {
+				prio = min(prio, w.prio)
+			}
+		case priorityCeiling:
+			prio = min(prio, r.ceiling)
+		}
+	}
+	if prio != t.prio {
+		t.prio = prio
+		if s.ready.contains(t) {
+			s.ready.fix(t)
+		}
+	}
+}
+
+// recordInversion records that r ran over [start, stop), which is an inversion if a task of
+// higher priority, by the priorities given in the workload, waited meanwhile.
+func (s *Simulation) recordInversion(r *task, start, stop int64) {
+	var waiting *task
+	consider := func(t *task) {
+		if t.Priority < r.Priority && (waiting == nil || t.Priority < waiting.Priority) {
+			waiting = t
+		}
+	}
+	for _, res := range s.resources {
+		for _, w := range res.waiters {
+			consider(w)
+		}
+	}
+	for _, t := range s.ready.all() {
+		consider(t)
+	}
+	if waiting == nil {
+		return
+	}
+	if n := len(s.inversions); n > 0 && s.inversions[n-1].Stop == start &&
+		s.inversions[n-1].PID == r.ProcessID && s.inversions[n-1].Waiting == waiting.ProcessID {
+		s.inversions[n-1].Stop = stop
+		return
+	}
+	s.inversions = append(s.inversions, Inversion{PID: r.ProcessID, Waiting: waiting.ProcessID, Start: start, Stop: stop})
+}
@@ -0,0 +1,190 @@
+package sched
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// inversion is the classic unbounded priority inversion: L holds R when H needs it, and M,
+// of neither, keeps L from running.
+var inversion = []Process{
+	{ProcessID: "L", BurstDuration: 6, Priority: 3, Locks: []Lock{{Resource: "R", Acquire: 1, Release: 5}}},
+	{ProcessID: "M", ArrivalTime: 2, BurstDuration: 4, Priority: 2},
+	{ProcessID: "H", ArrivalTime: 3, BurstDuration: 3, Priority: 1, Locks: []Lock{{Resource: "R", Acquire: 0, Release: 2}}},
+}
+
+func Test_simulate_locks(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		algorithm      string
+		wantGantt      []TimeSlice
+		wantBlocked    []LockWait
+		wantInversions []Inversion
+	}{
+		{
+			algorithm: "sjfp",
+			wantGantt: []TimeSlice{
+				{PID: "L", Start: 0, Stop: 2},
+				{PID: "M", Start: 2, Stop: 6},
+				{PID: "L", Start: 6, Stop: 9},
+				{PID: "H", Start: 9, Stop: 12},
+				{PID: "L", Start: 12, Stop: 13},
+			},
+			wantBlocked: []LockWait{{PID: "H", Resource: "R", Start: 3, Stop: 9}},
+			wantInversions: []Inversion{
+				{PID: "M", Waiting: "H", Start: 3, Stop: 6},
+				{PID: "L", Waiting: "H", Start: 6, Stop: 9},
+			},
+		},
+		{
+			algorithm: "sjfp-inherit",
+			wantGantt: []TimeSlice{
+				{PID: "L", Start: 0, Stop: 2},
+				{PID: "M", Start: 2, Stop: 3},
+				{PID: "L", Start: 3, Stop: 6},
+				{PID: "H", Start: 6, Stop: 9},
+				{PID: "M", Start: 9, Stop: 12},
+				{PID: "L", Start: 12, Stop: 13},
+			},
+			wantBlocked:    []LockWait{{PID: "H", Resource: "R", Start: 3, Stop: 6}},
+			wantInversions: []Inversion{{PID: "L", Waiting: "H", Start: 3, Stop: 6}},
+		},
+		{
+			algorithm: "sjfp-ceiling",
+			wantGantt: []TimeSlice{
+				{PID: "L", Start: 0, Stop: 5},
+				{PID: "H", Start: 5, Stop: 8},
+				{PID: "M", Start: 8, Stop: 12},
+				{PID: "L", Start: 12, Stop: 13},
+			},
+			wantInversions: []Inversion{
+				{PID: "L", Waiting: "M", Start: 2, Stop: 3},
+				{PID: "L", Waiting: "H", Start: 3, Stop: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.algorithm, func(t *testing.T) {
+			t.Parallel()
+			alg, err := LookupAlgorithm(tt.algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := Run(alg, alg.Defaults(), Machine{}, inversion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			if diff := cmp.Diff(tt.wantBlocked, res.Blocked); diff != "" {
+				t.Errorf(diff)
+			}
+			if diff := cmp.Diff(tt.wantInversions, res.Inversions); diff != "" {
+				t.Errorf(diff)
+			}
+			if err := CheckInvariants(inversion, res, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_validateLocks(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		processes []Process
+		wantErr   error
+	}{
+		{
+			name: "nested in the same order",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5, Locks: []Lock{{"R1", 0, 4}, {"R2", 1, 3}}},
+				{ProcessID: "B", BurstDuration: 5, Locks: []Lock{{"R1", 1, 2}, {"R2", 2, 5}}},
+			},
+		},
+		{
+			name: "outside the burst",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 3, Locks: []Lock{{"R1", 1, 4}}},
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "released before acquired",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 3, Locks: []Lock{{"R1", 2, 2}}},
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "held twice",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5, Locks: []Lock{{"R1", 0, 3}, {"R1", 2, 4}}},
+			},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name: "acquired in opposite orders",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5, Locks: []Lock{{"R1", 0, 4}, {"R2", 1, 3}}},
+				{ProcessID: "B", BurstDuration: 5, Locks: []Lock{{"R2", 0, 4}, {"R1", 1, 3}}},
+			},
+			wantErr: ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := validateLocks(tt.processes); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseLocks(t *testing.T) {
+	t.Parallel()
+	got, err := ParseLocks(" R1:0.5-2; R2:1-1.5 ", Timescale{Decimals: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Lock{{"R1", 5, 20}, {"R2", 10, 15}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	if got := FormatLocks(got, Timescale{Decimals: 1}); got != "R1:0.5-2.0;R2:1.0-1.5" {
+		t.Errorf("FormatLocks() = %q", got)
+	}
+	if _, err := ParseLocks("R1:2", Timescale{}); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidArgs)
+	}
+}
+
+// withLocks gives each process up to two nested critical sections, always acquiring the
+// resources in order, so that the workload can't deadlock.
+func withLocks(r *rand.Rand, processes []Process, resources int) []Process {
+	for i := range processes {
+		p := &processes[i]
+		if p.BurstDuration < 1 || r.IntN(3) == 0 {
+			continue
+		}
+		first := r.IntN(resources)
+		acquire := r.Int64N(p.BurstDuration)
+		release := acquire + 1 + r.Int64N(p.BurstDuration-acquire)
+		p.Locks = []Lock{{Resource: fmt.Sprint("R", first), Acquire: acquire, Release: release}}
+		if first+1 < resources && release-acquire > 1 && r.IntN(2) == 0 {
+			inner := acquire + r.Int64N(release-acquire-1)
+			p.Locks = append(p.Locks, Lock{Resource: fmt.Sprint("R", first+1+r.IntN(resources-first-1)),
+				Acquire: inner, Release: inner + 1 + r.Int64N(release-inner-1)})
+		}
+	}
+	return processes
+}
@@ -0,0 +1,217 @@
+package sched
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+//region Output helpers
+
+// OutputTitle writes title, underlined, above a schedule.
+func OutputTitle(w io.Writer, title string) {
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
+	_, _ = fmt.Fprintln(w, strings.Repeat(" ", len(title)/2), title)
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
+}
+
+// OutputGantt writes the Gantt chart of gantt, a schedule in whole ticks.
+func OutputGantt(w io.Writer, gantt []TimeSlice) {
+	outputGantt(w, gantt, Timescale{})
+}
+
+// outputGantt writes the chart in a single pass, marking idle time between slices with -.
+func outputGantt(w io.Writer, gantt []TimeSlice, ts Timescale) {
+	_, _ = fmt.Fprintln(w, "Gantt schedule")
+
+	buffer := 2
+	widest := 0
+	for _, slice := range gantt {
+		widest = max(widest, len(slice.PID), len(ts.FormatTime(slice.Stop))-buffer-1)
+	}
+	width := buffer + widest + buffer + 1
+
+	var bars, axis strings.Builder
+	cell := func(pid string, start int64) {
+		bars.WriteString(strings.Repeat(" ", buffer))
+		_, _ = fmt.Fprintf(&bars, "%-*s", widest, pid)
+		bars.WriteString(strings.Repeat(" ", buffer) + "|")
+		t := ts.FormatTime(start)
+		axis.WriteString(t)
+		axis.WriteString(strings.Repeat(" ", max(width-len(t), 1)))
+	}
+	bars.WriteString("|")
+	for i, slice := range gantt {
+		if i > 0 && slice.Start > gantt[i-1].Stop {
+			cell("-", gantt[i-1].Stop)
+		}
+		cell(slice.PID, slice.Start)
+	}
+	if n := len(gantt); n > 0 {
+		axis.WriteString(ts.FormatTime(gantt[n-1].Stop))
+	}
+	_, _ = fmt.Fprintln(w, bars.String())
+	_, _ = fmt.Fprint(w, axis.String())
+
+	_, _ = fmt.Fprintf(w, "\n\n")
+}
+
+// OutputResult writes res as its title, a Gantt chart, the schedule table with its averages,
+// and whatever else res measured, such as energy or swaps.
+func OutputResult(w io.Writer, res Result) {
+	header, rows := scheduleTable(res)
+	OutputTitle(w, res.Title)
+	switch {
+	case len(res.Blocked) > 0 || len(res.Inversions) > 0:
+		outputLocks(w, res)
+	case len(res.Gantt) > 0:
+		outputGantt(w, res.Gantt, res.Timescale)
+	}
+	outputSchedule(w, header, rows, res.Timescale, res.AverageWait, res.AverageTurnaround, res.Throughput)
+	outputTotals(w, res)
+}
+
+// scheduleTable returns the header and rows of the schedule table, with the columns res uses.
+func scheduleTable(res Result) ([]string, [][]string) {
+	ts := res.Timescale
+	memory := res.MemoryLimit > 0
+	header := []string{"ID", "Priority", "Burst"}
+	if res.Prediction != nil {
+		header = append(header, "Predicted")
+	}
+	header = append(header, "Arrival")
+	if memory {
+		header = append(header, "Memory", "Admission")
+	}
+	locks := len(res.Blocked) > 0 || len(res.Inversions) > 0
+	if locks {
+		header = append(header, "Blocked")
+	}
+	header = append(header, "Wait", "Turnaround", "Exit")
+	rows := make([][]string, len(res.Rows))
+	for i, r := range res.Rows {
+		rows[i] = []string{
+			fmt.Sprint(r.ProcessID),
+			fmt.Sprint(r.Priority),
+			ts.FormatTime(r.BurstDuration),
+		}
+		if res.Prediction != nil {
+			rows[i] = append(rows[i], ts.FormatMean(r.Predicted))
+		}
+		rows[i] = append(rows[i], ts.FormatTime(r.ArrivalTime))
+		if memory {
+			rows[i] = append(rows[i], fmt.Sprint(r.Memory), ts.FormatTime(r.Admission))
+		}
+		if locks {
+			rows[i] = append(rows[i], ts.FormatTime(r.Blocked))
+		}
+		rows[i] = append(rows[i],
+			ts.FormatTime(r.Wait),
+			ts.FormatTime(r.Turnaround),
+			ts.FormatTime(r.Exit),
+		)
+	}
+	return header, rows
+}
+
+// outputTotals outputs what res measured beyond the averages of its schedule.
+func outputTotals(w io.Writer, res Result) {
+	ts := res.Timescale
+	if res.Energy > 0 {
+		_, _ = fmt.Fprintf(w, "Energy: %.2f\n", res.Energy)
+		_, _ = fmt.Fprintf(w, "Energy-delay product: %.2f\n", res.EDP)
+	}
+	if p := res.Prediction; p != nil {
+		_, _ = fmt.Fprintf(w, "Mean absolute prediction error: %s%s (alpha %.2f, tau0 %s)\n",
+			ts.FormatMean(p.MeanAbsoluteError), ts.unitSuffix(), p.Alpha, ts.FormatMean(p.Tau0))
+		_, _ = fmt.Fprintf(w, "Penalty vs oracle SJF: %s average wait (oracle %s), %s average turnaround (oracle %s)\n",
+			ts.FormatChange(res.AverageWait-p.OracleWait), ts.FormatMean(p.OracleWait),
+			ts.FormatChange(res.AverageTurnaround-p.OracleTurnaround), ts.FormatMean(p.OracleTurnaround))
+	}
+	if res.MemoryLimit > 0 {
+		_, _ = fmt.Fprintf(w, "Average admission delay: %s%s\n", ts.FormatMean(res.AverageAdmission), ts.unitSuffix())
+		_, _ = fmt.Fprintf(w, "Swaps: %d (memory limit %d)\n", res.Swaps, res.MemoryLimit)
+	}
+	if len(res.Inversions) > 0 {
+		outputInversions(w, res.Inversions, ts)
+	}
+	if len(res.Groups) > 0 {
+		outputGroups(w, res.Groups, ts)
+	}
+}
+
+// outputLocks outputs the schedule above a row for every process that blocked on a resource,
+// showing the resource it waited for, and a row of the processes whose priority was inverted.
+func outputLocks(w io.Writer, res Result) {
+	charts := []Result{{Title: "CPU", Gantt: res.Gantt}}
+	waited := make(map[string][]TimeSlice)
+	for _, b := range res.Blocked {
+		waited[b.PID] = append(waited[b.PID], TimeSlice{PID: b.Resource, Start: b.Start, Stop: b.Stop})
+	}
+	for _, r := range res.Rows {
+		if waits := waited[r.ProcessID]; len(waits) > 0 {
+			charts = append(charts, Result{Title: r.ProcessID + " blocked", Gantt: waits})
+		}
+	}
+	if len(res.Inversions) > 0 {
+		inverted := make([]TimeSlice, len(res.Inversions))
+		for i, inv := range res.Inversions {
+			inverted[i] = TimeSlice{PID: inv.Waiting, Start: inv.Start, Stop: inv.Stop}
+		}
+		charts = append(charts, Result{Title: "inverted", Gantt: inverted})
+	}
+	outputStackedGantt(w, "Gantt schedule, with blocking", res.Timescale, charts...)
+}
+
+func outputInversions(w io.Writer, inversions []Inversion, ts Timescale) {
+	var total int64
+	for _, inv := range inversions {
+		total += inv.Stop - inv.Start
+	}
+	_, _ = fmt.Fprintf(w, "Priority inversion: %s%s in total\n", ts.FormatTime(total), ts.unitSuffix())
+	for _, inv := range inversions {
+		_, _ = fmt.Fprintf(w, "  %s ran [%s, %s) while %s waited\n",
+			inv.PID, ts.FormatTime(inv.Start), ts.FormatTime(inv.Stop), inv.Waiting)
+	}
+}
+
+func outputGroups(w io.Writer, groups []GroupShare, ts Timescale) {
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Group shares")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Group", "Processes", "CPU", "Contended CPU", "Contended share"})
+	for _, g := range groups {
+		table.Append([]string{
+			g.Group,
+			fmt.Sprint(g.Processes),
+			ts.FormatTime(g.CPU),
+			ts.FormatTime(g.Contended),
+			fmt.Sprintf("%.1f%%", 100*g.Share),
+		})
+	}
+	table.Render()
+}
+
+func outputSchedule(w io.Writer, header []string, rows [][]string, ts Timescale, wait, turnaround, throughput float64) {
+	_, _ = fmt.Fprintln(w, "Schedule table")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.Render()
+	outputAverages(w, ts, wait, turnaround, throughput)
+}
+
+func outputAverages(w io.Writer, ts Timescale, wait, turnaround, throughput float64) {
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "Average wait: %s%s\n", ts.FormatMean(wait), ts.unitSuffix())
+	_, _ = fmt.Fprintf(w, "Average turnaround: %s%s\n", ts.FormatMean(turnaround), ts.unitSuffix())
+	throughputUnit := ""
+	if ts.Unit != "" {
+		throughputUnit = " per " + ts.Unit
+	}
+	_, _ = fmt.Fprintf(w, "Throughput: %s%s\n", ts.FormatRate(throughput), throughputUnit)
+}
+
+//endregion
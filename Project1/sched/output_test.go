@@ -0,0 +1,102 @@
+package sched
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_outputGantt(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		gantt []TimeSlice
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wantW string
+	}{
+		{
+			name: "consecutive processes",
+			args: args{
+				gantt: []TimeSlice{
+					{PID: "A", Start: 1, Stop: 2},
+					{PID: "B", Start: 2, Stop: 4},
+					{PID: "C", Start: 4, Stop: 7},
+					{PID: "D", Start: 7, Stop: 11},
+					{PID: "E", Start: 11, Stop: 16},
+				},
+			},
+			wantW: `Gantt schedule
+|  A  |  B  |  C  |  D  |  E  |
+1     2     4     7     11    16
+
+`,
+		},
+		{
+			name: "nonconsecutive processes",
+			args: args{
+				gantt: []TimeSlice{
+					{PID: "A", Start: 1, Stop: 2},
+					{PID: "B", Start: 5, Stop: 6},
+					{PID: "C", Start: 6, Stop: 7},
+					{PID: "D", Start: 9, Stop: 11},
+					{PID: "E", Start: 13, Stop: 16},
+				},
+			},
+			wantW: `Gantt schedule
+|  A  |  -  |  B  |  C  |  -  |  D  |  -  |  E  |
+1     2     5     6     7     9     11    13    16
+
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := &bytes.Buffer{}
+			OutputGantt(w, tt.args.gantt)
+			if diff := cmp.Diff(tt.wantW, w.String()); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func Test_outputGantt_keepsSlices(t *testing.T) {
+	t.Parallel()
+	gantt := make([]TimeSlice, 0, 8)
+	gantt = append(gantt, TimeSlice{PID: "A", Start: 0, Stop: 2}, TimeSlice{PID: "B", Start: 4, Stop: 5},
+		TimeSlice{PID: "C", Start: 7, Stop: 9})
+	want := slices.Clone(gantt)
+	OutputGantt(io.Discard, gantt)
+	if diff := cmp.Diff(want, gantt); diff != "" {
+		t.Errorf("the chart changed its slices:\n%s", diff)
+	}
+}
+
+// BenchmarkOutputResult renders the schedules of generated workloads of growing size, which
+// should take O(n log n): each row and slice takes time in its width, and the widths grow with
+// the digits of the IDs and times, so the time per row only grows by a little at each size.
+func BenchmarkOutputResult(b *testing.B) {
+	for _, n := range []int{1_000, 10_000, 100_000} {
+		alg, err := LookupAlgorithm("rr")
+		if err != nil {
+			b.Fatal(err)
+		}
+		res, err := Run(alg, alg.Defaults(), Machine{}, largeWorkload(n))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				OutputResult(io.Discard, res)
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/row")
+		})
+	}
+}
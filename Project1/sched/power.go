@@ -0,0 +1,193 @@
+package sched
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// powerLevel is a CPU frequency the CPU can run at.
+	powerLevel struct {
+		speed int64   // work done per tick, relative to the other levels
+		power float64 // energy used per tick running at this speed
+	}
+
+	// PowerModel is the energy use of a CPU with several frequency levels.
+	PowerModel struct {
+		levels []powerLevel // slowest first
+		idle   float64      // energy used per tick idling
+	}
+)
+
+// DefaultPowerModel has four levels, with power growing roughly with the cube of the frequency.
+const DefaultPowerModel = "1:0.5,2:1.5,3:3.5,4:8,idle:0.2"
+
+// ParsePowerModel parses a comma separated list of <speed>:<power> levels and an optional
+// idle:<power> entry, or "default" for defaultPowerModel.
+func ParsePowerModel(spec string) (*PowerModel, error) {
+	if spec == "default" {
+		spec = DefaultPowerModel
+	}
+	m := &PowerModel{}
+	for _, entry := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: power level %q isn't <speed>:<power>", ErrInvalidArgs, entry)
+		}
+		power, err := strconv.ParseFloat(value, 64)
+		if err != nil || power < 0 {
+			return nil, fmt.Errorf("%w: power %q isn't a positive number", ErrInvalidArgs, value)
+		}
+		if name == "idle" {
+			m.idle = power
+			continue
+		}
+		speed, err := strconv.ParseInt(name, 10, 64)
+		if err != nil || speed < 1 {
+			return nil, fmt.Errorf("%w: speed %q isn't a positive integer", ErrInvalidArgs, name)
+		}
+		m.levels = append(m.levels, powerLevel{speed: speed, power: power})
+	}
+	if len(m.levels) == 0 {
+		return nil, fmt.Errorf("%w: power model %q has no levels", ErrInvalidArgs, spec)
+	}
+	slices.SortFunc(m.levels, func(a, b powerLevel) int { return cmp.Compare(a.speed, b.speed) })
+	for i := 1; i < len(m.levels); i++ {
+		if m.levels[i].speed == m.levels[i-1].speed {
+			return nil, fmt.Errorf("%w: speed %d is listed twice", ErrInvalidArgs, m.levels[i].speed)
+		}
+	}
+
+	return m, nil
+}
+
+// full returns the fastest level.
+func (m *PowerModel) full() powerLevel { return m.levels[len(m.levels)-1] }
+
+// describe names level l, e.g. "speed 2/4 (50%)".
+func (m *PowerModel) describe(l powerLevel) string {
+	return fmt.Sprintf("speed %d/%d (%.0f%%)", l.speed, m.full().speed, 100*float64(l.speed)/float64(m.full().speed))
+}
+
+// deadlines tracks whether the queued tasks, run in deadline order after the running one, all
+// meet their deadlines at each level, in O(levels × log n) per task queued or dequeued. It
+// relies on the policy ordering the ready queue by deadline, as scaling the frequency does.
+//
+// A task only finishes at the end of a tick, so at speed v its work w takes up ⌈w / v⌉ × v of
+// the CPU, the work rounded up to whole ticks. Running work w first, from time now, the tasks
+// all meet their deadlines at speed v if every one of them has W ≤ v × (d − now) − w, where W
+// is that rounded work of the queued tasks up to and including it in deadline order, w is
+// rounded too, and d is its deadline. So for each level, a segment tree over all the tasks in
+// deadline order keeps W − v × d, less absent for the tasks that aren't queued, and the
+// condition is that the greatest is at most −v × now − w.
+type deadlines struct {
+	rank   []int // of each task by index, in deadline order
+	queued []bool
+	work   []int64
+	speeds []int64   // of each level
+	trees  []maxTree // by level
+}
+
+// absent is far below any W − v × d of a queued task.
+const absent = int64(1) << 60
+
+func newDeadlines(tasks []*task, levels []powerLevel) *deadlines {
+	order := slices.Clone(tasks)
+	slices.SortStableFunc(order, func(a, b *task) int { return cmp.Compare(a.deadline, b.deadline) })
+	d := &deadlines{rank: make([]int, len(tasks)), queued: make([]bool, len(tasks)), work: make([]int64, len(tasks))}
+	for i, t := range order {
+		d.rank[t.index] = i
+	}
+	values := make([]int64, len(order))
+	for _, l := range levels {
+		for i, t := range order {
+			values[i] = -l.speed*t.deadline - absent
+		}
+		d.speeds = append(d.speeds, l.speed)
+		d.trees = append(d.trees, newMaxTree(values))
+	}
+	return d
+}
+
+// queue adds t, with work left to do, to the queued tasks, or updates its work if it's queued.
+func (d *deadlines) queue(t *task, work int64) {
+	d.dequeue(t)
+	r := d.rank[t.index]
+	d.queued[r], d.work[r] = true, work
+	for i := range d.trees {
+		d.trees[i].add(r, len(d.rank), roundUp(work, d.speeds[i]))
+		d.trees[i].add(r, r+1, absent)
+	}
+}
+
+// dequeue removes t from the queued tasks, if it's queued.
+func (d *deadlines) dequeue(t *task) {
+	r := d.rank[t.index]
+	if !d.queued[r] {
+		return
+	}
+	d.queued[r] = false
+	for i := range d.trees {
+		d.trees[i].add(r, len(d.rank), -roundUp(d.work[r], d.speeds[i]))
+		d.trees[i].add(r, r+1, -absent)
+	}
+}
+
+// meet reports whether the queued tasks all meet their deadlines at level i, of speed v, after
+// running work first from time now.
+func (d *deadlines) meet(i int, v, now, work int64) bool {
+	return len(d.rank) == 0 || d.trees[i].max() <= -v*now-roundUp(work, v)
+}
+
+// roundUp rounds work up to whole ticks at speed v.
+func roundUp(work, v int64) int64 { return (work + v - 1) / v * v }
+
+// maxTree is a segment tree of values, supporting adding to a range of them and finding the
+// greatest, in O(log n).
+type maxTree struct {
+	n       int
+	top     []int64 // greatest value under each node
+	pending []int64 // added to every value under each node
+}
+
+func newMaxTree(values []int64) maxTree {
+	t := maxTree{n: len(values), top: make([]int64, 4*len(values)), pending: make([]int64, 4*len(values))}
+	if t.n > 0 {
+		t.build(1, 0, t.n, values)
+	}
+	return t
+}
+
+func (t *maxTree) build(node, lo, hi int, values []int64) {
+	if hi-lo == 1 {
+		t.top[node] = values[lo]
+		return
+	}
+	mid := (lo + hi) / 2
+	t.build(2*node, lo, mid, values)
+	t.build(2*node+1, mid, hi, values)
+	t.top[node] = max(t.top[2*node], t.top[2*node+1])
+}
+
+// add adds v to the values in [from, to).
+func (t *maxTree) add(from, to int, v int64) { t.update(1, 0, t.n, from, to, v) }
+
+func (t *maxTree) update(node, lo, hi, from, to int, v int64) {
+	if to <= lo || hi <= from {
+		return
+	}
+	if from <= lo && hi <= to {
+		t.top[node] += v
+		t.pending[node] += v
+		return
+	}
+	mid := (lo + hi) / 2
+	t.update(2*node, lo, mid, from, to, v)
+	t.update(2*node+1, mid, hi, from, to, v)
+	t.top[node] = max(t.top[2*node], t.top[2*node+1]) + t.pending[node]
+}
+
+func (t *maxTree) max() int64 { return t.top[1] }
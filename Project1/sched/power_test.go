@@ -0,0 +1,215 @@
+package sched
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parsePowerModel(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		spec    string
+		want    *PowerModel
+		wantErr error
+	}{
+		{
+			name: "levels are sorted",
+			spec: "4:8, 1:0.5,idle:0.1",
+			want: &PowerModel{levels: []powerLevel{{speed: 1, power: 0.5}, {speed: 4, power: 8}}, idle: 0.1},
+		},
+		{
+			name: "default",
+			spec: "default",
+			want: &PowerModel{
+				levels: []powerLevel{{speed: 1, power: 0.5}, {speed: 2, power: 1.5}, {speed: 3, power: 3.5}, {speed: 4, power: 8}},
+				idle:   0.2,
+			},
+		},
+		{
+			name:    "no levels",
+			spec:    "idle:1",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "not a level",
+			spec:    "fast",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "fractional speed",
+			spec:    "0.5:1",
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "duplicate speed",
+			spec:    "2:1,2:3",
+			wantErr: ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePowerModel(tt.spec)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parsePowerModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(PowerModel{}, powerLevel{})); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func Test_simulate_energy(t *testing.T) {
+	t.Parallel()
+	alg, err := LookupAlgorithm("energy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := alg.Policy(alg.Defaults(), Machine{})
+	p.stretch = 2
+	tests := []struct {
+		name       string
+		processes  []Process
+		wantGantt  []TimeSlice
+		wantEnergy float64
+		wantEDP    float64
+	}{
+		{
+			name:       "slows down to just meet the deadline",
+			processes:  []Process{{ProcessID: "A", BurstDuration: 2}},
+			wantGantt:  []TimeSlice{{PID: "A", Start: 0, Stop: 4, Speed: 0.5}},
+			wantEnergy: 6,
+			wantEDP:    24,
+		},
+		{
+			name: "leaves room for a known arrival",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2},
+				{ProcessID: "B", BurstDuration: 1, ArrivalTime: 1},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 0, Stop: 1, Speed: 1},
+				{PID: "B", Start: 1, Stop: 2, Speed: 1},
+				{PID: "A", Start: 2, Stop: 4, Speed: 0.5},
+			},
+			wantEnergy: 19,
+			wantEDP:    76,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := Simulate(p, tt.processes, nil).Result(alg.Title)
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			if !closeTo(res.Energy, tt.wantEnergy) || !closeTo(res.EDP, tt.wantEDP) {
+				t.Errorf("energy = %.2f, EDP = %.2f, want %.2f, %.2f", res.Energy, res.EDP, tt.wantEnergy, tt.wantEDP)
+			}
+			if err := CheckInvariants(tt.processes, res, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Test_simulate_energy_feasible checks that whenever every deadline is met at full speed, the
+// energy algorithm meets them all too, using no more energy.
+func Test_simulate_energy_feasible(t *testing.T) {
+	t.Parallel()
+	alg, err := LookupAlgorithm("energy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewPCG(4600, 38))
+	workloads := [][]Process{loadWorkload(t, "testdata/workloads/example.csv")}
+	for i := 0; i < 500; i++ {
+		workloads = append(workloads, randomWorkload(r, 1+r.IntN(12)))
+	}
+	var feasible int
+	for i, processes := range workloads {
+		params := map[string]int64{"stretch": 1 + r.Int64N(4)}
+		p := alg.Policy(params, Machine{})
+		full := p
+		full.dvfs = false
+		want := Simulate(full, processes, nil).Result(alg.Title)
+		if missed(want, params["stretch"]) != "" {
+			continue
+		}
+		feasible++
+		got := Simulate(p, processes, nil).Result(alg.Title)
+		if m := missed(got, params["stretch"]); m != "" {
+			t.Fatalf("workload %d, stretch %d: %s misses its deadline, met at full speed:\n%v", i, params["stretch"], m, processes)
+		}
+		if got.Energy > want.Energy+1e-9 {
+			t.Fatalf("workload %d, stretch %d: energy = %.2f, %.2f at full speed:\n%v", i, params["stretch"], got.Energy, want.Energy, processes)
+		}
+	}
+	if feasible < len(workloads)/4 {
+		t.Errorf("only %d of %d workloads are feasible at full speed", feasible, len(workloads))
+	}
+}
+
+// missed returns the ID of a process in res that exits after arrival + stretch × burst.
+func missed(res Result, stretch int64) string {
+	for _, row := range res.Rows {
+		if row.Exit > row.ArrivalTime+stretch*row.BurstDuration {
+			return row.ProcessID
+		}
+	}
+	return ""
+}
+
+func Test_deadlines(t *testing.T) {
+	t.Parallel()
+	m, err := ParsePowerModel("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewPCG(4900, 1))
+	for i := 0; i < 200; i++ {
+		tasks := make([]*task, 1+r.IntN(20))
+		for j := range tasks {
+			tasks[j] = &task{index: j, deadline: r.Int64N(30)}
+		}
+		d := newDeadlines(tasks, m.levels)
+		ready := make(map[*task]int64)
+		for step := 0; step < 50; step++ {
+			tk := tasks[r.IntN(len(tasks))]
+			if _, ok := ready[tk]; ok {
+				d.dequeue(tk)
+				delete(ready, tk)
+			} else {
+				ready[tk] = 1 + r.Int64N(20)
+				d.queue(tk, ready[tk])
+			}
+
+			// every task meets its deadline in deadline order, after the running work.
+			now, running := r.Int64N(10), r.Int64N(10)
+			queue := make([]*task, 0, len(ready))
+			for tk := range ready {
+				queue = append(queue, tk)
+			}
+			slices.SortFunc(queue, func(a, b *task) int { return int(a.deadline - b.deadline) })
+			for level, l := range m.levels {
+				// each task runs for whole ticks, so its work rounds up to them.
+				want, ticks := true, now+(running+l.speed-1)/l.speed
+				for _, tk := range queue {
+					ticks += (ready[tk] + l.speed - 1) / l.speed
+					want = want && ticks <= tk.deadline
+				}
+				if got := d.meet(level, l.speed, now, running); got != want {
+					t.Fatalf("meet(speed %d, now %d, running %d) = %v, want %v", l.speed, now, running, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package sched
+
+import "math"
+
+// PredictionStats measures how well bursts were predicted, and what mispredicting cost.
+type PredictionStats struct {
+	Alpha             float64 `json:"alpha"` // weight of the last burst in each prediction
+	Tau0              float64 `json:"tau0"`  // prediction before any burst has finished
+	MeanAbsoluteError float64 `json:"meanAbsoluteError"`
+	// OracleWait and OracleTurnaround are the averages of SJF knowing every burst in advance.
+	OracleWait       float64 `json:"oracleWait"`
+	OracleTurnaround float64 `json:"oracleTurnaround"`
+}
+
+// prediction is the next burst predicted for a group. The group's tasks that haven't run yet
+// are all predicted to need it, so they are queued in a class of their own, reordered as one
+// when it changes.
+type prediction struct {
+	tau    float64
+	queued class
+}
+
+var byPredicted = sortKey{name: "predicted remaining", smaller: "shorter job predicted", larger: "longer job predicted", value: func(t *task) int64 {
+	predicted := t.predicted
+	if t.ran == 0 && t.prediction != nil {
+		predicted = t.prediction.tau
+	}
+	return max(int64(math.Round(predicted))-(t.BurstDuration-t.remaining), 0)
+}}
+
+// predict gives the arrived task t the prediction of its group, from the bursts that have
+// finished so far. Each group keeps its own history, and processes without one share a history.
+func (s *Simulation) predict(t *task) {
+	p := s.predictions[t.Group]
+	if p == nil {
+		p = &prediction{tau: s.policy.tau0, queued: class{heap: taskHeap{policy: s.policy}}}
+		s.predictions[t.Group] = p
+	}
+	t.prediction, t.predicted = p, p.tau
+}
+
+// settle keeps the burst predicted for t once it first runs or finishes, after which other
+// bursts finishing no longer change it.
+func (t *task) settle() {
+	if t.ran == 0 && t.prediction != nil {
+		t.predicted = t.prediction.tau
+	}
+}
+
+// learn folds the burst of the finished task t into the prediction of its group's next burst,
+// τₙ₊₁ = α tₙ + (1 − α) τₙ, which is also the new prediction of the group's arrived tasks that
+// haven't run yet.
+func (s *Simulation) learn(t *task) {
+	p := t.prediction
+	p.tau = s.policy.alpha*float64(t.BurstDuration) + (1-s.policy.alpha)*p.tau
+	s.ready.fixClass(&p.queued)
+}
+
+// predictionStats compares the predicted bursts with the actual ones, and the schedule with
+// the one SJF would have made knowing every burst.
+func (s *Simulation) predictionStats() *PredictionStats {
+	stats := &PredictionStats{Alpha: s.policy.alpha, Tau0: s.policy.tau0}
+	processes := make([]Process, len(s.tasks))
+	for i, t := range s.tasks {
+		processes[i] = t.Process
+		stats.MeanAbsoluteError += math.Abs(t.predicted - float64(t.BurstDuration))
+	}
+	if len(s.tasks) > 0 {
+		stats.MeanAbsoluteError /= float64(len(s.tasks))
+	}
+	oracle := s.policy
+	oracle.keys, oracle.predict = sjf.policy().keys, false
+	res := Simulate(oracle, processes, nil).Result(sjf.title())
+	stats.OracleWait, stats.OracleTurnaround = res.AverageWait, res.AverageTurnaround
+
+	return stats
+}
@@ -0,0 +1,46 @@
+package sched
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_simulate_predicted(t *testing.T) {
+	t.Parallel()
+	alg, err := LookupAlgorithm("sjf-predict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// predicting the last burst of each group, the second short job is run first.
+	processes := []Process{
+		{ProcessID: "L1", BurstDuration: 8, Group: "long"},
+		{ProcessID: "S1", BurstDuration: 1, Group: "short"},
+		{ProcessID: "L2", BurstDuration: 8, ArrivalTime: 10, Group: "long"},
+		{ProcessID: "S2", BurstDuration: 1, ArrivalTime: 10, Group: "short"},
+	}
+	res := Simulate(alg.Policy(map[string]int64{"alpha": 100, "tau0": 4}, Machine{}), processes, nil).Result(alg.Title)
+	wantGantt := []TimeSlice{
+		{PID: "L1", Start: 0, Stop: 8},
+		{PID: "S1", Start: 8, Stop: 9},
+		{PID: "S2", Start: 10, Stop: 11},
+		{PID: "L2", Start: 11, Stop: 19},
+	}
+	if diff := cmp.Diff(wantGantt, res.Gantt); diff != "" {
+		t.Errorf(diff)
+	}
+	var predicted []float64
+	for _, r := range res.Rows {
+		predicted = append(predicted, r.Predicted)
+	}
+	if diff := cmp.Diff([]float64{4, 4, 8, 1}, predicted); diff != "" {
+		t.Errorf(diff)
+	}
+	wantStats := &PredictionStats{Alpha: 1, Tau0: 4, MeanAbsoluteError: 1.75, OracleWait: 0.5, OracleTurnaround: 5}
+	if diff := cmp.Diff(wantStats, res.Prediction); diff != "" {
+		t.Errorf(diff)
+	}
+	if res.AverageWait != 2.25 {
+		t.Errorf("average wait = %.2f, want 2.25", res.AverageWait)
+	}
+}
@@ -0,0 +1,142 @@
+package sched
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+type (
+	// OpenShape describes an open system, where processes keep arriving as a Poisson process.
+	OpenShape struct {
+		Rate    float64 // arrivals per tick
+		Service float64 // mean burst, in ticks
+		Dist    string  // distribution of bursts: exp, const or uniform
+		Horizon int64   // arrivals stop at the horizon
+	}
+
+	// SteadyState is the long run behavior of a queue, measured from a schedule or predicted.
+	SteadyState struct {
+		Processes    int     `json:"processes"`    // arrived within the measured window
+		Utilization  float64 `json:"utilization"`  // fraction of time the CPU was busy
+		MeanInSystem float64 `json:"meanInSystem"` // processes arrived but not exited, over time
+		MeanQueue    float64 `json:"meanQueue"`    // processes waiting, over time
+		MeanResponse float64 `json:"meanResponse"` // from arrival to exit
+		MeanWait     float64 `json:"meanWait"`     // from arrival to exit, less the burst
+	}
+)
+
+// ServiceDists are the distributions of bursts an open system can be generated with.
+var ServiceDists = []string{"exp", "const", "uniform"}
+
+func (s OpenShape) Validate() error {
+	switch {
+	case s.Rate <= 0 || s.Horizon < 1:
+		return fmt.Errorf("%w: open systems need a positive arrival rate and horizon", ErrInvalidArgs)
+	case s.Service < 1:
+		return fmt.Errorf("%w: mean service time %g is less than a tick", ErrInvalidArgs, s.Service)
+	case s.Dist != "exp" && s.Dist != "const" && s.Dist != "uniform":
+		return fmt.Errorf("%w: unknown service distribution %q, expected one of %v", ErrInvalidArgs, s.Dist, ServiceDists)
+	}
+	return nil
+}
+
+// GenerateOpen returns processes arriving as a Poisson process until the horizon, with bursts
+// drawn from the shape's distribution and rounded to whole ticks (at least one):
+// • exp is exponential, making the system M/M/1
+// • const is always the mean, making the system M/D/1
+// • uniform is uniform over [1, 2 × mean - 1]
+func GenerateOpen(r *rand.Rand, shape OpenShape) []Process {
+	var processes []Process
+	for t := r.ExpFloat64() / shape.Rate; t < float64(shape.Horizon); t += r.ExpFloat64() / shape.Rate {
+		var burst float64
+		switch shape.Dist {
+		case "exp":
+			burst = r.ExpFloat64() * shape.Service
+		case "const":
+			burst = shape.Service
+		case "uniform":
+			burst = 1 + r.Float64()*(2*shape.Service-2)
+		}
+		processes = append(processes, Process{
+			ProcessID:     fmt.Sprintf("P%d", len(processes)),
+			ArrivalTime:   int64(t),
+			BurstDuration: max(int64(math.Round(burst)), 1),
+			Priority:      1,
+		})
+	}
+
+	return processes
+}
+
+// MeasureSteadyState measures a schedule over the window [from, to), which should start after
+// the queue has warmed up, and end before arrivals stop. Means over time cover the whole
+// window, while response and wait are averaged over the processes arriving within it.
+func MeasureSteadyState(res Result, from, to int64) SteadyState {
+	var (
+		st               SteadyState
+		busy, inSystem   int64
+		response, waited int64
+	)
+	overlap := func(start, stop int64) int64 { return max(min(stop, to)-max(start, from), 0) }
+	for _, slice := range res.Gantt {
+		busy += overlap(slice.Start, slice.Stop)
+	}
+	for _, r := range res.Rows {
+		inSystem += overlap(r.ArrivalTime, r.Exit)
+		if r.ArrivalTime < from || r.ArrivalTime >= to {
+			continue
+		}
+		st.Processes++
+		response += r.Turnaround
+		waited += r.Wait + r.Admission
+	}
+	if window := float64(to - from); window > 0 {
+		st.Utilization = float64(busy) / window
+		st.MeanInSystem = float64(inSystem) / window
+		st.MeanQueue = st.MeanInSystem - st.Utilization
+	}
+	if st.Processes > 0 {
+		st.MeanResponse = float64(response) / float64(st.Processes)
+		st.MeanWait = float64(waited) / float64(st.Processes)
+	}
+
+	return st
+}
+
+// MG1 predicts the steady state of an M/G/1 FCFS queue from the Pollaczek–Khinchine formula,
+// given the arrival rate and the first two moments of the service time, E[S] and E[S²].
+// It returns false when the queue is unstable, i.e. the utilization λE[S] is at least one.
+func MG1(rate, mean, second float64) (SteadyState, bool) {
+	rho := rate * mean
+	if rho >= 1 {
+		return SteadyState{Utilization: rho}, false
+	}
+	wait := rate * second / (2 * (1 - rho))
+	return SteadyState{
+		Utilization:  rho,
+		MeanInSystem: rate * (wait + mean),
+		MeanQueue:    rate * wait,
+		MeanResponse: wait + mean,
+		MeanWait:     wait,
+	}, true
+}
+
+// MM1 predicts the steady state of an M/M/1 FCFS queue, where E[S²] = 2E[S]².
+func MM1(rate, mean float64) (SteadyState, bool) {
+	return MG1(rate, mean, 2*mean*mean)
+}
+
+// ServiceMoments returns the first two moments of the bursts of processes, E[S] and E[S²].
+func ServiceMoments(processes []Process) (mean, second float64) {
+	for _, p := range processes {
+		b := float64(p.BurstDuration)
+		mean += b
+		second += b * b
+	}
+	if n := float64(len(processes)); n > 0 {
+		mean /= n
+		second /= n
+	}
+	return mean, second
+}
@@ -0,0 +1,97 @@
+package sched
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestMG1(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		rate       float64
+		mean       float64
+		second     float64
+		want       SteadyState
+		wantStable bool
+	}{
+		{
+			name:       "M/M/1",
+			rate:       0.5,
+			mean:       1,
+			second:     2,
+			want:       SteadyState{Utilization: 0.5, MeanInSystem: 1, MeanQueue: 0.5, MeanResponse: 2, MeanWait: 1},
+			wantStable: true,
+		},
+		{
+			name:       "M/D/1 waits half as long",
+			rate:       0.5,
+			mean:       1,
+			second:     1,
+			want:       SteadyState{Utilization: 0.5, MeanInSystem: 0.75, MeanQueue: 0.25, MeanResponse: 1.5, MeanWait: 0.5},
+			wantStable: true,
+		},
+		{
+			name:   "unstable",
+			rate:   0.5,
+			mean:   2,
+			second: 8,
+			want:   SteadyState{Utilization: 1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, stable := MG1(tt.rate, tt.mean, tt.second)
+			if stable != tt.wantStable {
+				t.Errorf("MG1() stable = %v, want %v", stable, tt.wantStable)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func TestMeasureSteadyState(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: "A", BurstDuration: 4},
+		{ProcessID: "B", BurstDuration: 2, ArrivalTime: 2},
+		{ProcessID: "C", BurstDuration: 2, ArrivalTime: 12},
+	}
+	res := Simulate(fcfs.policy(), processes, nil).Result(fcfs.title())
+	// over [2, 12): A is in the system for 2 ticks, B for 4, and the CPU busy for 4.
+	want := SteadyState{Processes: 1, Utilization: 0.4, MeanInSystem: 0.6, MeanQueue: 0.2, MeanResponse: 4, MeanWait: 2}
+	if diff := cmp.Diff(want, MeasureSteadyState(res, 2, 12), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestGenerateOpen(t *testing.T) {
+	t.Parallel()
+	shape := OpenShape{Rate: 0.01, Service: 50, Dist: "exp", Horizon: 1_000_000}
+	processes := GenerateOpen(rand.New(rand.NewPCG(4600, 0)), shape)
+	if got, want := float64(len(processes)), shape.Rate*float64(shape.Horizon); math.Abs(got/want-1) > 0.05 {
+		t.Errorf("%v arrivals, want about %v", got, want)
+	}
+	mean, second := ServiceMoments(processes)
+	if math.Abs(mean/shape.Service-1) > 0.05 || math.Abs(second/(2*shape.Service*shape.Service)-1) > 0.1 {
+		t.Errorf("E[S] = %.2f, E[S²] = %.2f, want about %v and %v", mean, second, shape.Service, 2*shape.Service*shape.Service)
+	}
+	for _, p := range processes {
+		if p.BurstDuration < 1 || p.ArrivalTime < 0 || p.ArrivalTime >= shape.Horizon {
+			t.Fatalf("process %+v out of bounds", p)
+		}
+	}
+
+	if err := (OpenShape{Rate: 1, Service: 1, Dist: "pareto", Horizon: 1}).Validate(); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidArgs)
+	}
+}
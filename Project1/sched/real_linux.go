@@ -0,0 +1,112 @@
+//go:build linux
+
+package sched
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+type (
+	// commands run processes as their commands, paused with SIGSTOP and resumed with SIGCONT.
+	commands struct {
+		children []*child // by task index, of the commands that have started
+		done     chan *task
+	}
+
+	// child is the command of a process.
+	child struct {
+		cmd    *exec.Cmd
+		gate   *os.File      // closed to start the command
+		waited chan struct{} // closed once the command has exited
+	}
+)
+
+// RunReal launches the command of every process in the simulation at its arrival time, and
+// schedules them under the simulation's policy (see dispatch) by pausing and resuming them with
+// SIGSTOP and SIGCONT, with one tick lasting tick.
+// Bursts are only estimates used to order the ready queue: a process runs until its command exits.
+// A command doesn't start until its process is first dispatched: the shell running it waits for
+// its gate, a pipe closed by the dispatcher, so that a quick command can't finish before it's paused.
+func RunReal(sim *Simulation, tick time.Duration) (LiveResult, error) {
+	for _, t := range sim.arrivals {
+		if t.Command == "" {
+			return LiveResult{Timescale: sim.policy.clock}, fmt.Errorf("%w: process %s has no command to run", ErrInvalidArgs, t.ProcessID)
+		}
+	}
+	return dispatch(sim, tick, &commands{
+		children: make([]*child, len(sim.tasks)),
+		// buffered for every command, so that none is left waiting to report its exit.
+		done: make(chan *task, len(sim.tasks)),
+	})
+}
+
+func (cs *commands) arrive(t *task) error {
+	gate, open, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	c := &child{
+		cmd:    exec.Command("sh", "-c", `read -r _ <&3; exec 3<&-; eval "$1"`, "sh", t.Command),
+		gate:   open,
+		waited: make(chan struct{}),
+	}
+	c.cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.cmd.ExtraFiles = []*os.File{gate}
+	err = c.cmd.Start()
+	_ = gate.Close()
+	if err != nil {
+		_ = open.Close()
+		return err
+	}
+	cs.children[t.index] = c
+	go func() {
+		_ = c.cmd.Wait()
+		close(c.waited)
+		cs.done <- t
+	}()
+	return nil
+}
+
+func (cs *commands) resume(t *task) {
+	c := cs.children[t.index]
+	if c.gate != nil {
+		_ = c.gate.Close()
+		c.gate = nil
+		return
+	}
+	c.signal(syscall.SIGCONT)
+}
+
+// pause stops the command of t, which may yet turn out to have exited first.
+func (cs *commands) pause(t *task) bool {
+	cs.children[t.index].signal(syscall.SIGSTOP)
+	return false
+}
+
+func (cs *commands) exits() <-chan *task { return cs.done }
+
+func (cs *commands) stop() {
+	for _, c := range cs.children {
+		if c == nil {
+			continue
+		}
+		select {
+		case <-c.waited:
+		default:
+			c.signal(syscall.SIGKILL)
+			<-c.waited
+		}
+		if c.gate != nil {
+			_ = c.gate.Close()
+		}
+	}
+}
+
+// signal sends sig to the command's whole process group, in case it forked.
+func (c *child) signal(sig syscall.Signal) {
+	_ = syscall.Kill(-c.cmd.Process.Pid, sig)
+}
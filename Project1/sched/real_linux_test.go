@@ -0,0 +1,83 @@
+//go:build linux
+
+package sched
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_runReal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		scheduler scheduler
+		processes []Process
+		wantOrder []string
+		wantErr   error
+	}{
+		{
+			name:      "fcfs runs commands one at a time",
+			scheduler: fcfs,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2, ArrivalTime: 0, Command: "sleep 0.02"},
+				{ProcessID: "B", BurstDuration: 1, ArrivalTime: 0, Command: "sleep 0.01"},
+				{ProcessID: "C", BurstDuration: 1, ArrivalTime: 1, Command: "true"},
+			},
+			wantOrder: []string{"A", "B", "C"},
+		},
+		{
+			name:      "sjf runs the shorter estimate first",
+			scheduler: sjf,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 3, ArrivalTime: 0, Command: "sleep 0.02"},
+				{ProcessID: "B", BurstDuration: 1, ArrivalTime: 0, Command: "sleep 0.01"},
+			},
+			wantOrder: []string{"B", "A"},
+		},
+		{
+			name:      "sjfp runs the higher priority first",
+			scheduler: sjfp,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 3, ArrivalTime: 0, Priority: 2, Command: "sleep 0.01"},
+				{ProcessID: "B", BurstDuration: 2, ArrivalTime: 0, Priority: 1, Command: "sleep 0.01"},
+				{ProcessID: "C", BurstDuration: 1, ArrivalTime: 0, Priority: 3, Command: "sleep 0.01"},
+			},
+			wantOrder: []string{"B", "A", "C"},
+		},
+		{
+			name:      "missing command",
+			scheduler: fcfs,
+			processes: []Process{{ProcessID: "A", BurstDuration: 1}},
+			wantErr:   ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := RunReal(Simulate(tt.scheduler.policy(), tt.processes, nil), 10*time.Millisecond)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("runReal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			rows := slices.Clone(res.Rows)
+			slices.SortFunc(rows, func(a, b LiveRow) int { return cmp.Compare(a.Exit, b.Exit) })
+			order := make([]string, len(rows))
+			for i, r := range rows {
+				order[i] = r.ProcessID
+				if r.Wait < 0 || r.Turnaround <= 0 {
+					t.Errorf("%s measured wait %.2f, turnaround %.2f", r.ProcessID, r.Wait, r.Turnaround)
+				}
+			}
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("finished in order %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
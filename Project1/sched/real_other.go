@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sched
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunReal is only supported on Linux, where processes can be paused with SIGSTOP.
+func RunReal(*Simulation, time.Duration) (LiveResult, error) {
+	return LiveResult{}, fmt.Errorf("%w: real processes can only be scheduled on Linux", ErrInvalidArgs)
+}
@@ -0,0 +1,450 @@
+// Package sched simulates CPU scheduling: it loads workloads of processes, runs them under
+// the registered algorithms on a simulated machine, checks the schedules and renders them.
+package sched
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+type (
+	Process struct {
+		ProcessID     string `json:"id"`
+		ArrivalTime   int64  `json:"arrival"`
+		BurstDuration int64  `json:"burst"`
+		Priority      int64  `json:"priority"`
+		Group         string `json:"group,omitempty"`   // job the process belongs to, for group scheduling
+		Memory        int64  `json:"memory,omitempty"`  // only limited by -memory
+		Command       string `json:"command,omitempty"` // only run by -real
+		Locks         []Lock `json:"locks,omitempty"`   // critical sections, in CPU time into the burst
+		// Parent and IO are only simulated for threads: the process a thread belongs to, and
+		// the blocking calls it makes.
+		Parent string    `json:"process,omitempty"`
+		IO     []IOBurst `json:"io,omitempty"`
+	}
+	TimeSlice struct {
+		PID   string `json:"pid"`
+		Start int64  `json:"start"`
+		Stop  int64  `json:"stop"`
+		// Speed is the fraction of full speed the CPU ran at, when it was scaled.
+		Speed float64 `json:"speed,omitempty"`
+	}
+	// ProcessResult is the timing of a single process in a schedule.
+	ProcessResult struct {
+		Process
+		Predicted  float64 `json:"predicted,omitempty"` // burst predicted when first dispatched
+		Admission  int64   `json:"admission,omitempty"` // waiting for (or swapped out of) memory
+		Blocked    int64   `json:"blocked,omitempty"`   // waiting for resources held by others
+		Wait       int64   `json:"wait"`                // waiting in the ready queue
+		Turnaround int64   `json:"turnaround"`
+		Exit       int64   `json:"exit"`
+	}
+	// Result is a complete schedule and its averages.
+	Result struct {
+		Title             string           `json:"title"`
+		Gantt             []TimeSlice      `json:"gantt"`
+		Rows              []ProcessResult  `json:"rows"`
+		AverageWait       float64          `json:"averageWait"`
+		AverageTurnaround float64          `json:"averageTurnaround"`
+		Throughput        float64          `json:"throughput"`
+		AverageAdmission  float64          `json:"averageAdmission,omitempty"`
+		MemoryLimit       int64            `json:"memoryLimit,omitempty"`
+		Swaps             int              `json:"swaps,omitempty"`
+		Groups            []GroupShare     `json:"groups,omitempty"`
+		Energy            float64          `json:"energy,omitempty"`
+		EDP               float64          `json:"edp,omitempty"` // energy × makespan
+		Prediction        *PredictionStats `json:"prediction,omitempty"`
+		Blocked           []LockWait       `json:"blocked,omitempty"`
+		Inversions        []Inversion      `json:"inversions,omitempty"`
+		Timescale                          // that times, all counted in ticks, are written in
+	}
+	// GroupShare is the CPU time received by a group of processes.
+	GroupShare struct {
+		Group     string  `json:"group"`
+		Processes int     `json:"processes"`
+		CPU       int64   `json:"cpu"`
+		Contended int64   `json:"contended"` // CPU received while other groups had work
+		Share     float64 `json:"share"`     // of all CPU given while groups contended
+	}
+)
+
+// groupKey tells groups apart: a process without a group is a group of its own, named after
+// the process, which isn't the group of that name.
+type groupKey struct {
+	name string
+	solo bool
+}
+
+// group returns the group of p, a process without one being a group of its own.
+func (p Process) group() groupKey {
+	if p.Group == "" {
+		return groupKey{name: p.ProcessID, solo: true}
+	}
+	return groupKey{name: p.Group}
+}
+
+type (
+	// Param is a tunable of an algorithm, set on the command line as -<name>.
+	Param struct {
+		Name    string
+		Usage   string
+		Default int64
+		Min     int64
+		Max     int64 // zero is unbounded
+		Time    bool  // a length of time, in whole units of the machine's clock
+	}
+	// Algorithm is a scheduler that can be run by name.
+	Algorithm struct {
+		Name   string
+		Title  string
+		Usage  string
+		Params []Param
+		policy func(params map[string]int64) Policy
+	}
+	// Machine is the hardware processes are scheduled on, beyond its single CPU.
+	Machine struct {
+		Memory int64       // shared by admitted processes, zero is unlimited
+		Swap   bool        // swap out ready processes to admit ones that would run before them
+		Power  *PowerModel // energy is only reported with a power model
+		Clock  Timescale   // what a tick of the simulated clock is
+	}
+)
+
+// algorithms are all the registered algorithms, in the order they are listed.
+var algorithms = []Algorithm{
+	{
+		Name:   fcfs.String(),
+		Title:  fcfs.title(),
+		Usage:  "First-come, first-serve scheduling",
+		policy: func(map[string]int64) Policy { return fcfs.policy() },
+	},
+	{
+		Name:   sjf.String(),
+		Title:  sjf.title(),
+		Usage:  "Shortest-job-first scheduling",
+		policy: func(map[string]int64) Policy { return sjf.policy() },
+	},
+	{
+		Name:   sjfp.String(),
+		Title:  sjfp.title(),
+		Usage:  "Shortest-job-first with priority scheduling",
+		policy: func(map[string]int64) Policy { return sjfp.policy() },
+	},
+	{
+		Name:  rr.String(),
+		Title: rr.title(),
+		Usage: "Round-robin scheduling",
+		Params: []Param{
+			{Name: "quantum", Usage: "time slice length", Default: 1, Min: 1, Time: true},
+		},
+		policy: func(params map[string]int64) Policy {
+			p := rr.policy()
+			p.quantum = params["quantum"]
+			return p
+		},
+	},
+	{
+		Name:  "sjf-predict",
+		Title: "Predicted shortest-job-first",
+		Usage: "Shortest-job-first, predicting each burst by exponential averaging of the previous bursts of its group",
+		Params: []Param{
+			{Name: "alpha", Usage: "weight of the last burst in each prediction, in percent", Default: 50, Min: 0, Max: 100},
+			{Name: "tau0", Usage: "prediction before any burst has finished", Default: 10, Min: 0, Time: true},
+		},
+		policy: func(params map[string]int64) Policy {
+			return Policy{
+				keys:       []sortKey{byPredicted},
+				preemptive: true,
+				predict:    true,
+				alpha:      float64(params["alpha"]) / 100,
+				tau0:       float64(params["tau0"]),
+			}
+		},
+	},
+	{
+		Name:  "energy",
+		Title: "Energy-aware EDF",
+		Usage: "Earliest-deadline-first, slowing the CPU as far as every deadline allows (uses -power)",
+		Params: []Param{
+			{Name: "stretch", Usage: "deadlines are arrival + stretch × burst", Default: 2, Min: 1},
+		},
+		policy: func(params map[string]int64) Policy {
+			return Policy{
+				keys:       []sortKey{byDeadline},
+				preemptive: true,
+				dvfs:       true,
+				stretch:    params["stretch"],
+			}
+		},
+	},
+	{
+		Name:  "group",
+		Title: "Group fair-share",
+		Usage: "Fair share between groups of processes, then between the processes of a group",
+		Params: []Param{
+			{Name: "quantum", Usage: "time slice length", Default: 1, Min: 1, Time: true},
+		},
+		policy: func(params map[string]int64) Policy {
+			return Policy{keys: []sortKey{byGroupCPU, byCPU}, quantum: params["quantum"]}
+		},
+	},
+	{
+		Name:  "sjfp-inherit",
+		Title: "Priority with inheritance",
+		Usage: "Priority scheduling, where a process holding a resource inherits the priority of those it blocks",
+		policy: func(map[string]int64) Policy {
+			p := sjfp.policy()
+			p.protocol = inheritPriority
+			return p
+		},
+	},
+	{
+		Name:  "sjfp-ceiling",
+		Title: "Priority with ceiling",
+		Usage: "Priority scheduling, where a process holding a resource runs at the highest priority of any process using it",
+		policy: func(map[string]int64) Policy {
+			p := sjfp.policy()
+			p.protocol = priorityCeiling
+			return p
+		},
+	},
+}
+
+// Algorithms returns all the registered algorithms, in the order they are listed.
+func Algorithms() []Algorithm { return slices.Clone(algorithms) }
+
+// LookupAlgorithm finds a registered algorithm by name, or parses a policy expression (see
+// ParseExpression).
+func LookupAlgorithm(name string) (Algorithm, error) {
+	for _, a := range algorithms {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	if isExpression(name) {
+		return ParseExpression(name)
+	}
+	return Algorithm{}, fmt.Errorf("%w: unknown algorithm %q (see list)", ErrInvalidArgs, name)
+}
+
+// Defaults returns the default value of every param.
+func (a Algorithm) Defaults() map[string]int64 {
+	params := make(map[string]int64, len(a.Params))
+	for _, p := range a.Params {
+		params[p.Name] = p.Default
+	}
+	return params
+}
+
+// Validate checks params are within their bounds.
+func (a Algorithm) Validate(params map[string]int64) error {
+	for _, p := range a.Params {
+		if params[p.Name] < p.Min {
+			return fmt.Errorf("%w: -%s must be at least %d", ErrInvalidArgs, p.Name, p.Min)
+		}
+		if p.Max > 0 && params[p.Name] > p.Max {
+			return fmt.Errorf("%w: -%s must be at most %d", ErrInvalidArgs, p.Name, p.Max)
+		}
+	}
+	return nil
+}
+
+// Policy returns the policy of a running with params on machine m, params that are times
+// being converted to ticks of its clock. Algorithms that scale the CPU's frequency use
+// DefaultPowerModel when m has no power model.
+func (a Algorithm) Policy(params map[string]int64, m Machine) Policy {
+	ticks := maps.Clone(params)
+	for _, param := range a.Params {
+		if param.Time {
+			ticks[param.Name] *= m.Clock.ticks()
+		}
+	}
+	p := a.policy(ticks)
+	p.memory, p.swap, p.power, p.clock = m.Memory, m.Swap, m.Power, m.Clock
+	if p.dvfs && p.power == nil {
+		p.power, _ = ParsePowerModel(DefaultPowerModel)
+	}
+
+	return p
+}
+
+// Run runs a with params on machine m over processes, after validating all three.
+func Run(a Algorithm, params map[string]int64, m Machine, processes []Process) (Result, error) {
+	if err := a.Validate(params); err != nil {
+		return Result{}, err
+	}
+	p := a.Policy(params, m)
+	if err := p.Validate(processes); err != nil {
+		return Result{}, err
+	}
+
+	return Simulate(p, processes, nil).Result(a.Title), nil
+}
+
+// groupShares totals the CPU time received by each group of rows, both overall and while
+// contending with other groups, i.e. while another group had a process that hadn't exited.
+func groupShares(rows []ProcessResult, gantt []TimeSlice) []GroupShare {
+	type change struct {
+		at    int64
+		group int
+		delta int // processes arriving, or exiting when negative
+	}
+	var (
+		shares    []GroupShare
+		index     = make(map[groupKey]int) // group to its share
+		groupOf   = make(map[string]int)   // process to its group's share
+		changes   []change
+		contended int64
+	)
+	for _, r := range rows {
+		g := r.group()
+		i, ok := index[g]
+		if !ok {
+			i = len(shares)
+			index[g] = i
+			shares = append(shares, GroupShare{Group: g.name})
+		}
+		shares[i].Processes++
+		groupOf[r.ProcessID] = i
+		if r.ArrivalTime < r.Exit {
+			changes = append(changes, change{r.ArrivalTime, i, 1}, change{r.Exit, i, -1})
+		}
+	}
+	slices.SortFunc(changes, func(a, b change) int { return cmp.Compare(a.at, b.at) })
+
+	// sweep through the times contention changes, counting the groups with processes, to find
+	// the intervals where more than one has.
+	var (
+		times      []int64 // when contention changes
+		contending []bool  // from each time until the next
+		processes  = make([]int, len(shares))
+		groups     int
+	)
+	for i := 0; i < len(changes); {
+		at := changes[i].at
+		for ; i < len(changes) && changes[i].at == at; i++ {
+			c := changes[i]
+			if processes[c.group] == 0 {
+				groups++
+			}
+			processes[c.group] += c.delta
+			if processes[c.group] == 0 {
+				groups--
+			}
+		}
+		times = append(times, at)
+		contending = append(contending, groups > 1)
+	}
+
+	for _, slice := range gantt {
+		share := &shares[groupOf[slice.PID]]
+		share.CPU += slice.Stop - slice.Start
+		// the interval containing the slice's start, then those after it until it stops.
+		i, found := slices.BinarySearch(times, slice.Start)
+		if !found {
+			i--
+		}
+		for start := slice.Start; start < slice.Stop; i++ {
+			stop := slice.Stop
+			if i+1 < len(times) {
+				stop = min(stop, times[i+1])
+			}
+			if i >= 0 && contending[i] {
+				share.Contended += stop - start
+				contended += stop - start
+			}
+			start = stop
+		}
+	}
+	if contended > 0 {
+		for i := range shares {
+			shares[i].Share = float64(shares[i].Contended) / float64(contended)
+		}
+	}
+
+	return shares
+}
+
+// Summarize averages the timing of every process in a schedule.
+func Summarize(title string, gantt []TimeSlice, rows []ProcessResult) Result {
+	res := Result{Title: title, Gantt: gantt, Rows: rows}
+	var (
+		totalWait       float64
+		totalTurnaround float64
+		totalAdmission  float64
+		lastCompletion  int64
+	)
+	for _, r := range rows {
+		totalWait += float64(r.Wait)
+		totalAdmission += float64(r.Admission)
+		totalTurnaround += float64(r.Turnaround)
+		lastCompletion = max(lastCompletion, r.Exit)
+	}
+	if count := float64(len(rows)); count > 0 {
+		res.AverageWait = totalWait / count
+		res.AverageTurnaround = totalTurnaround / count
+		res.AverageAdmission = totalAdmission / count
+	}
+	// processes that all finish at time 0 have no throughput to measure.
+	if lastCompletion > 0 {
+		res.Throughput = float64(len(rows)) / float64(lastCompletion)
+	}
+
+	return res
+}
+
+//region Schedulers
+
+// FCFSSchedule outputs a schedule of processes in a GANTT chart and a table of timing given:
+// • an output writer
+// • a title for the chart
+// • a slice of processes
+func FCFSSchedule(w io.Writer, title string, processes []Process) {
+	OutputResult(w, Simulate(fcfs.policy(), processes, nil).Result(title))
+}
+
+// SJFSchedule outputs a preemptive shortest-job-first (shortest remaining time) schedule.
+func SJFSchedule(w io.Writer, title string, processes []Process) {
+	OutputResult(w, Simulate(sjf.policy(), processes, nil).Result(title))
+}
+
+// SJFPrioritySchedule outputs a preemptive priority schedule, breaking ties by shortest remaining time.
+func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
+	OutputResult(w, Simulate(sjfp.policy(), processes, nil).Result(title))
+}
+
+// RRSchedule outputs a round-robin schedule with a time quantum of 1.
+func RRSchedule(w io.Writer, title string, processes []Process) {
+	OutputResult(w, Simulate(rr.policy(), processes, nil).Result(title))
+}
+
+//endregion
+
+// scheduler is one of the schedulers the original assignment asked for, named by its flag.
+//
+//go:generate stringer -type=scheduler
+type scheduler uint
+
+const (
+	fcfs scheduler = iota + 1
+	sjf
+	sjfp
+	rr
+)
+
+// title is the heading printed above the scheduler's output.
+func (s scheduler) title() string {
+	switch s {
+	case sjf:
+		return "Shortest-job-first"
+	case sjfp:
+		return "Priority"
+	case rr:
+		return "Round-robin"
+	default:
+		return "First-come, first-serve"
+	}
+}
@@ -0,0 +1,184 @@
+package sched
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFCFSSchedule(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		processes []Process
+		title     string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOut string
+	}{
+		{
+			name: "default",
+			args: args{
+				processes: []Process{
+					{
+						ProcessID:     "P0",
+						ArrivalTime:   0,
+						BurstDuration: 5,
+						Priority:      2,
+					},
+					{
+						ProcessID:     "P1",
+						ArrivalTime:   3,
+						BurstDuration: 9,
+						Priority:      1,
+					},
+					{
+						ProcessID:     "P2",
+						ArrivalTime:   6,
+						BurstDuration: 6,
+						Priority:      3,
+					},
+				},
+				title: "First-come, first-serve",
+			},
+			wantOut: loadFixture(t, "testdata", "fcfs_fixture.txt"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var w bytes.Buffer
+			FCFSSchedule(&w, tt.args.title, tt.args.processes)
+			if diff := cmp.Diff(w.String(), tt.wantOut); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func loadFixture(t *testing.T, p ...string) string {
+	b, err := os.ReadFile(path.Join(p...))
+	if err != nil {
+		t.Fail()
+	}
+
+	return string(b)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	rr, err := LookupAlgorithm("rr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processes := []Process{
+		{ProcessID: "A", BurstDuration: 2, Memory: 4},
+		{ProcessID: "B", BurstDuration: 1, Memory: 4},
+	}
+	tests := []struct {
+		name      string
+		params    map[string]int64
+		machine   Machine
+		processes []Process // if not those above
+		wantGantt []TimeSlice
+		wantErr   error
+	}{
+		{
+			name:   "runs",
+			params: map[string]int64{"quantum": 1},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 0, Stop: 1},
+				{PID: "B", Start: 1, Stop: 2},
+				{PID: "A", Start: 2, Stop: 3},
+			},
+		},
+		{
+			name:    "params are validated",
+			params:  map[string]int64{"quantum": 0},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "processes must fit the machine",
+			params:  rr.Defaults(),
+			machine: Machine{Memory: 2},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:      "bursts can't be negative",
+			params:    rr.Defaults(),
+			processes: []Process{{ProcessID: "A", BurstDuration: -3}},
+			wantErr:   ErrInvalidArgs,
+		},
+		{
+			name:      "IDs must be unique",
+			params:    rr.Defaults(),
+			processes: []Process{{ProcessID: "A", BurstDuration: 2}, {ProcessID: "A", BurstDuration: 1}},
+			wantErr:   ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ps := processes
+			if tt.processes != nil {
+				ps = tt.processes
+			}
+			res, err := Run(rr, tt.params, tt.machine, ps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		rows           []ProcessResult
+		wantThroughput float64
+	}{
+		{
+			name: "processes per unit of time",
+			rows: []ProcessResult{
+				{Process: Process{ProcessID: "A", BurstDuration: 2}, Turnaround: 2, Exit: 2},
+				{Process: Process{ProcessID: "B", BurstDuration: 2}, Wait: 2, Turnaround: 4, Exit: 4},
+			},
+			wantThroughput: 0.5,
+		},
+		{
+			name: "no time has passed when every burst is zero",
+			rows: []ProcessResult{
+				{Process: Process{ProcessID: "A"}},
+				{Process: Process{ProcessID: "B"}},
+			},
+		},
+		{
+			name: "no processes",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := Summarize("test", nil, tt.rows)
+			if res.Throughput != tt.wantThroughput {
+				t.Errorf("Summarize() throughput = %v, want %v", res.Throughput, tt.wantThroughput)
+			}
+			if _, err := json.Marshal(res); err != nil {
+				t.Errorf("json.Marshal() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,868 @@
+package sched
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+type (
+	// task is the simulation's view of a Process.
+	task struct {
+		Process
+		index        int   // position in the input
+		remaining    int64 // CPU time still needed at full speed, rounded up
+		carry        int64 // work done towards the next tick of remaining, in cycles
+		ran          int64 // time spent running, longer than the burst when slowed down
+		deadline     int64
+		seq          uint64 // enqueue order, the FIFO tie-breaker
+		pos          int    // in the heap of its class, while ready
+		exit         int64
+		since        int64       // when it last started waiting for memory
+		admission    int64       // time spent waiting for memory
+		predicted    float64     // burst predicted when it first ran, when the policy predicts bursts
+		prediction   *prediction // of its group, until it first runs
+		group        *group
+		prio         int64     // priority it runs at, raised while holding resources under a protocol
+		ops          []lockOp  // lock operations of its burst, in order
+		nextOp       int       // next entry in ops
+		blockedOn    *resource // resource it waits for, if blocked
+		blockedSince int64     // when it last blocked
+		blocked      int64     // time spent blocked on resources
+	}
+
+	// group is the fair-share clock of a group of tasks.
+	group struct {
+		cpu    int64 // CPU time received, caught up to the other groups' when it becomes active
+		active int   // tasks arrived but not finished
+		pos    int   // in the heap of active groups
+		queued class // its ready tasks, under a policy with shared keys
+	}
+
+	// groupHeap is the active groups, least CPU time received first.
+	groupHeap []*group
+
+	// sortKey is a single term of a ready queue ordering.
+	sortKey struct {
+		name  string
+		value func(t *task) int64
+		desc  bool
+		// smaller and larger describe a task ranked ahead by a smaller or a larger value.
+		smaller, larger string
+		// shared keys also change while the task is queued, as other tasks of its group run.
+		shared bool
+	}
+
+	// Policy describes how a scheduler picks the next process to run.
+	Policy struct {
+		keys       []sortKey // ready queue order, ties are broken FIFO
+		preemptive bool      // a better ready process preempts the running one
+		quantum    int64     // time slice length, zero means run until done
+		memory     int64     // memory shared by admitted processes, zero means unlimited
+		swap       bool      // swap out ready processes to admit a better one
+		power      *PowerModel
+		dvfs       bool         // slow the CPU down as far as deadlines allow
+		stretch    int64        // deadlines are arrival + stretch × burst, zero means none
+		predict    bool         // predict bursts by exponential averaging, instead of knowing them
+		alpha      float64      // weight of the last burst in each prediction
+		tau0       float64      // prediction before any burst has finished
+		protocol   lockProtocol // how holding a resource raises a process's priority
+		clock      Timescale
+	}
+
+	// EventKind is the type of scheduling decision recorded in an Event.
+	EventKind string
+
+	// Event is a single decision made by the simulation.
+	Event struct {
+		Time      int64     `json:"time"`
+		Kind      EventKind `json:"kind"`
+		PID       string    `json:"pid"`
+		Remaining int64     `json:"remaining"`
+		Reason    string    `json:"reason,omitempty"`
+	}
+)
+
+const (
+	EventArrival  EventKind = "arrival"
+	EventEnqueue  EventKind = "enqueue"
+	EventDispatch EventKind = "dispatch"
+	EventPreempt  EventKind = "preempt"
+	EventComplete EventKind = "complete"
+	EventAdmit    EventKind = "admit"
+	EventSwapOut  EventKind = "swap-out"
+	EventSpeed    EventKind = "speed"
+	EventLock     EventKind = "lock"
+	EventBlock    EventKind = "block"
+	EventUnlock   EventKind = "unlock"
+	EventInherit  EventKind = "inherit"
+)
+
+var (
+	byArrival = sortKey{name: "arrival", smaller: "earlier arrival", larger: "later arrival",
+		value: func(t *task) int64 { return t.ArrivalTime }}
+	byBurst = sortKey{name: "burst", smaller: "shorter burst", larger: "longer burst",
+		value: func(t *task) int64 { return t.BurstDuration }}
+	byRemaining = sortKey{name: "remaining", smaller: "shorter job", larger: "longer job",
+		value: func(t *task) int64 { return t.remaining }}
+	byPriority = sortKey{name: "priority", smaller: "higher priority", larger: "lower priority",
+		value: func(t *task) int64 { return t.prio }}
+	byDeadline = sortKey{name: "deadline", smaller: "earlier deadline", larger: "later deadline",
+		value: func(t *task) int64 { return t.deadline }}
+	byCPU = sortKey{name: "cpu", smaller: "less CPU received", larger: "more CPU received",
+		value: func(t *task) int64 { return t.BurstDuration - t.remaining }}
+	byGroupCPU = sortKey{name: "group cpu", smaller: "group with less CPU", larger: "group with more CPU", shared: true, value: func(t *task) int64 {
+		if t.group == nil {
+			return 0 // not simulated
+		}
+		return t.group.cpu
+	}}
+)
+
+// policy returns the scheduling policy implementing the scheduler.
+func (s scheduler) policy() Policy {
+	switch s {
+	case sjf:
+		return Policy{keys: []sortKey{byRemaining}, preemptive: true}
+	case sjfp:
+		return Policy{keys: []sortKey{byPriority, byRemaining}, preemptive: true}
+	case rr:
+		return Policy{quantum: 1}
+	default:
+		return Policy{keys: []sortKey{byArrival}}
+	}
+}
+
+// Validate checks that processes can all be run by p.
+func (p Policy) Validate(processes []Process) error {
+	switch {
+	case p.memory < 0:
+		return fmt.Errorf("%w: memory limit %d is negative", ErrInvalidArgs, p.memory)
+	case p.swap && p.memory == 0:
+		return fmt.Errorf("%w: swapping needs a memory limit", ErrInvalidArgs)
+	case p.dvfs && p.power == nil:
+		return fmt.Errorf("%w: scaling the CPU's frequency needs a power model", ErrInvalidArgs)
+	}
+	if err := validateLocks(processes); err != nil {
+		return err
+	}
+	ids := make(map[string]bool, len(processes))
+	for _, proc := range processes {
+		if ids[proc.ProcessID] {
+			return fmt.Errorf("%w: more than one process has the ID %s", ErrInvalidArgs, proc.ProcessID)
+		}
+		ids[proc.ProcessID] = true
+		if proc.BurstDuration < 0 {
+			return fmt.Errorf("%w: process %s has negative burst %d", ErrInvalidArgs, proc.ProcessID, proc.BurstDuration)
+		}
+		if proc.Memory < 0 {
+			return fmt.Errorf("%w: process %s needs negative memory %d", ErrInvalidArgs, proc.ProcessID, proc.Memory)
+		}
+		if p.memory > 0 && proc.Memory > p.memory {
+			return fmt.Errorf("%w: process %s needs memory %d, more than the limit of %d",
+				ErrInvalidArgs, proc.ProcessID, proc.Memory, p.memory)
+		}
+	}
+	return nil
+}
+
+// shared reports whether any key of the policy changes while tasks are queued.
+func (p Policy) shared() bool {
+	return slices.ContainsFunc(p.keys, func(k sortKey) bool { return k.shared })
+}
+
+// compare orders two tasks by the policy's keys alone.
+func (p Policy) compare(a, b *task) int {
+	for _, k := range p.keys {
+		x, y := k.value(a), k.value(b)
+		if k.desc {
+			x, y = y, x
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// preemption explains why the ready task a preempts the running task b: the first key ranking
+// a ahead, and whether a has just arrived or was already waiting.
+func (p Policy) preemption(a, b *task, now int64) string {
+	when := " ready"
+	if a.ArrivalTime == now {
+		when = " arrived"
+	}
+	for _, k := range p.keys {
+		x, y := k.value(a), k.value(b)
+		switch {
+		case x < y && !k.desc:
+			return k.smaller + when
+		case x > y && k.desc:
+			return k.larger + when
+		}
+	}
+	return "better process" + when
+}
+
+// describe explains why t sits at the head of the ready queue.
+func (p Policy) describe(t *task) string {
+	if len(p.keys) == 0 {
+		return "first in ready queue (FIFO)"
+	}
+	terms := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		terms[i] = fmt.Sprintf("%s=%d", k.name, k.value(t))
+	}
+	return "first in ready queue (" + strings.Join(terms, ", ") + ")"
+}
+
+//region Ready queue
+
+// readyQueue holds the ready tasks in the order the policy dispatches them, in O(log n) per
+// operation. Tasks whose shared keys (see sortKey) are equal, and change together, are kept in
+// a class: each class is a heap of its tasks, and the classes are a heap ordered by their first
+// tasks, so that a change to the shared keys of a class only moves the class.
+type readyQueue struct {
+	policy  Policy
+	classes classHeap
+	n       int
+	// classOf returns the class t is queued in while ready, nil for the tasks without shared
+	// keys, which are all queued in one.
+	classOf func(t *task) *class
+	other   *class
+}
+
+// class is a heap of ready tasks whose shared keys are equal.
+type class struct {
+	heap taskHeap
+	pos  int // in the heap of classes, while it has tasks
+}
+
+type taskHeap struct {
+	policy Policy
+	tasks  []*task
+}
+
+type classHeap struct {
+	policy  Policy
+	classes []*class
+}
+
+func newReadyQueue(p Policy) readyQueue {
+	return readyQueue{
+		policy:  p,
+		classes: classHeap{policy: p},
+		classOf: func(*task) *class { return nil },
+		other:   &class{heap: taskHeap{policy: p}},
+	}
+}
+
+// before orders two tasks by the policy's keys, then FIFO.
+func (p Policy) before(a, b *task) bool {
+	if c := p.compare(a, b); c != 0 {
+		return c < 0
+	}
+	return a.seq < b.seq
+}
+
+func (h *taskHeap) Len() int           { return len(h.tasks) }
+func (h *taskHeap) Less(i, j int) bool { return h.policy.before(h.tasks[i], h.tasks[j]) }
+func (h *taskHeap) Swap(i, j int) {
+	h.tasks[i], h.tasks[j] = h.tasks[j], h.tasks[i]
+	h.tasks[i].pos, h.tasks[j].pos = i, j
+}
+func (h *taskHeap) Push(x any) {
+	t := x.(*task)
+	t.pos = len(h.tasks)
+	h.tasks = append(h.tasks, t)
+}
+func (h *taskHeap) Pop() any {
+	n := len(h.tasks) - 1
+	t := h.tasks[n]
+	h.tasks[n] = nil
+	h.tasks = h.tasks[:n]
+	return t
+}
+
+func (h *classHeap) Len() int { return len(h.classes) }
+func (h *classHeap) Less(i, j int) bool {
+	return h.policy.before(h.classes[i].heap.tasks[0], h.classes[j].heap.tasks[0])
+}
+func (h *classHeap) Swap(i, j int) {
+	h.classes[i], h.classes[j] = h.classes[j], h.classes[i]
+	h.classes[i].pos, h.classes[j].pos = i, j
+}
+func (h *classHeap) Push(x any) {
+	c := x.(*class)
+	c.pos = len(h.classes)
+	h.classes = append(h.classes, c)
+}
+func (h *classHeap) Pop() any {
+	n := len(h.classes) - 1
+	c := h.classes[n]
+	h.classes[n] = nil
+	h.classes = h.classes[:n]
+	return c
+}
+
+func (q *readyQueue) Len() int { return q.n }
+
+func (q *readyQueue) class(t *task) *class {
+	if c := q.classOf(t); c != nil {
+		return c
+	}
+	return q.other
+}
+
+func (q *readyQueue) push(t *task) {
+	c := q.class(t)
+	heap.Push(&c.heap, t)
+	q.n++
+	if c.heap.Len() == 1 {
+		heap.Push(&q.classes, c)
+	} else {
+		heap.Fix(&q.classes, c.pos)
+	}
+}
+
+// peek returns the task that would be dispatched next.
+func (q *readyQueue) peek() *task { return q.classes.classes[0].heap.tasks[0] }
+
+func (q *readyQueue) pop() *task {
+	t := q.peek()
+	q.remove(t)
+	return t
+}
+
+func (q *readyQueue) remove(t *task) {
+	c := q.class(t)
+	heap.Remove(&c.heap, t.pos)
+	q.n--
+	if c.heap.Len() == 0 {
+		heap.Remove(&q.classes, c.pos)
+	} else {
+		heap.Fix(&q.classes, c.pos)
+	}
+}
+
+// contains reports whether t is queued.
+func (q *readyQueue) contains(t *task) bool {
+	c := q.class(t)
+	return t.pos < c.heap.Len() && c.heap.tasks[t.pos] == t
+}
+
+// fix reorders t, whose keys have changed while queued.
+func (q *readyQueue) fix(t *task) {
+	c := q.class(t)
+	heap.Fix(&c.heap, t.pos)
+	heap.Fix(&q.classes, c.pos)
+}
+
+// fixClass reorders c, whose shared keys have changed.
+func (q *readyQueue) fixClass(c *class) {
+	if c.heap.Len() > 0 {
+		heap.Fix(&q.classes, c.pos)
+	}
+}
+
+// all returns the queued tasks in no particular order.
+func (q *readyQueue) all() []*task {
+	tasks := make([]*task, 0, q.n)
+	for _, c := range q.classes.classes {
+		tasks = append(tasks, c.heap.tasks...)
+	}
+	return tasks
+}
+
+// sorted returns the queued tasks in the order they would be dispatched.
+func (q *readyQueue) sorted() []*task {
+	tasks := q.all()
+	slices.SortFunc(tasks, func(a, b *task) int {
+		if c := q.policy.compare(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+	return tasks
+}
+
+//endregion
+
+//region Simulation
+
+// Simulation is a discrete-event model of a single CPU running a policy.
+// When the policy limits memory, arriving processes wait in FIFO order to be admitted by a
+// long-term scheduler, and may be swapped back out by a medium-term one.
+type Simulation struct {
+	policy      Policy
+	now         int64
+	tasks       []*task // input order
+	arrivals    []*task // arrival order
+	next        int     // next entry in arrivals
+	pending     []*task // arrived, waiting to be admitted
+	swapped     []*task // swapped out, readmitted before pending
+	free        int64   // memory not used by admitted processes
+	swaps       int
+	groups      map[groupKey]*group
+	active      groupHeap              // when the policy has shared keys
+	predictions map[string]*prediction // next burst predicted for each group
+	deadlines   *deadlines             // of every unfinished task but scaled, when the policy scales the CPU's frequency
+	scaled      *task                  // the running task when the CPU was last scaled
+	resources   []*resource            // in order of first use
+	lockWaits   []LockWait
+	inversions  []Inversion
+	level       powerLevel // the CPU's current frequency
+	energy      float64
+	ready       readyQueue
+	running     *task
+	expired     *task // running task whose quantum just ran out
+	quantumEnd  int64
+	finished    int
+	seq         uint64
+	gantt       []TimeSlice
+	observe     func(Event)
+	// stream, if not nil, is given each slice of the Gantt chart once it ends, which is then
+	// not kept.
+	stream func(TimeSlice)
+}
+
+// newSimulation prepares processes to be run by p.
+// observe, if not nil, is called with every decision the simulation makes.
+func newSimulation(p Policy, processes []Process, observe func(Event)) *Simulation {
+	s := &Simulation{
+		policy:  p,
+		ready:   newReadyQueue(p),
+		tasks:   make([]*task, len(processes)),
+		free:    p.memory,
+		level:   powerLevel{speed: 1},
+		observe: observe,
+	}
+	if p.power != nil {
+		s.level = p.power.full()
+	}
+	s.groups = make(map[groupKey]*group)
+	s.predictions = make(map[string]*prediction)
+	switch {
+	case p.shared():
+		s.ready.classOf = func(t *task) *class { return &t.group.queued }
+	case p.predict:
+		s.ready.classOf = func(t *task) *class {
+			if t.ran == 0 {
+				return &t.prediction.queued
+			}
+			return nil
+		}
+	}
+	for i := range processes {
+		s.tasks[i] = p.newTask(i, processes[i])
+		for _, l := range processes[i].Locks {
+			r := s.resourceFor(l.Resource)
+			r.ceiling = min(r.ceiling, processes[i].Priority)
+		}
+		g := processes[i].group()
+		if s.groups[g] == nil {
+			s.groups[g] = &group{queued: class{heap: taskHeap{policy: p}}}
+		}
+		s.tasks[i].group = s.groups[g]
+	}
+	if p.dvfs {
+		s.deadlines = newDeadlines(s.tasks, p.power.levels)
+		for _, t := range s.tasks {
+			s.deadlines.queue(t, s.cycles(t))
+		}
+	}
+	s.arrivals = slices.Clone(s.tasks)
+	slices.SortStableFunc(s.arrivals, func(a, b *task) int {
+		return cmp.Compare(a.ArrivalTime, b.ArrivalTime)
+	})
+	if len(s.arrivals) > 0 && s.arrivals[0].ArrivalTime < 0 {
+		s.now = s.arrivals[0].ArrivalTime
+	}
+	s.decide()
+
+	return s
+}
+
+// newTask prepares process, the i-th in the input, to be run by p. Schedules run outside the
+// simulation build their tasks the same way, so that the policy orders them alike.
+func (p Policy) newTask(i int, process Process) *task {
+	t := &task{
+		Process:   process,
+		index:     i,
+		remaining: process.BurstDuration,
+		prio:      process.Priority,
+		ops:       lockOps(process.Locks),
+	}
+	if p.stretch > 0 {
+		t.deadline = process.ArrivalTime + p.stretch*process.BurstDuration
+	}
+	return t
+}
+
+// TraceEvents returns an observer writing every event to w as a line of JSON.
+func TraceEvents(w io.Writer) func(Event) {
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		_ = enc.Encode(e)
+	}
+}
+
+// Simulate runs processes under p to completion.
+func Simulate(p Policy, processes []Process, observe func(Event)) *Simulation {
+	s := newSimulation(p, processes, observe)
+	for !s.done() {
+		s.step(math.MaxInt64)
+	}
+	return s
+}
+
+func (s *Simulation) done() bool { return s.finished == len(s.tasks) }
+
+// step advances the clock to the next event, or to until if that comes first,
+// and then makes every scheduling decision due at the new time.
+func (s *Simulation) step(until int64) {
+	s.advance(min(s.nextEvent(), until))
+	s.decide()
+}
+
+func (s *Simulation) nextEvent() int64 {
+	next := int64(math.MaxInt64)
+	if s.next < len(s.arrivals) {
+		next = s.arrivals[s.next].ArrivalTime
+	}
+	if s.running != nil {
+		next = min(next, s.now+(s.cycles(s.running)+s.level.speed-1)/s.level.speed)
+		if s.policy.quantum > 0 && !s.renewing() {
+			next = min(next, s.quantumEnd)
+		}
+		if ticks, ok := s.untilLock(s.running); ok {
+			next = min(next, s.now+ticks)
+		}
+	}
+	return next
+}
+
+// renewing reports whether the running task's quantum would simply be renewed as it expires,
+// no other task being ready, so that the clock needn't stop for it. Under DVFS it must, as the
+// speed is chosen again at every decision.
+func (s *Simulation) renewing() bool {
+	return s.ready.Len() == 0 && !s.policy.dvfs
+}
+
+// cycles returns the work t still needs, in cycles of the fastest level.
+func (s *Simulation) cycles(t *task) int64 {
+	return t.remaining*s.fullSpeed() - t.carry
+}
+
+func (s *Simulation) fullSpeed() int64 {
+	if s.policy.power == nil {
+		return 1
+	}
+	return s.policy.power.full().speed
+}
+
+func (s *Simulation) advance(to int64) {
+	r := s.running
+	if to > s.now {
+		s.account(to-s.now, r != nil)
+	}
+	if r != nil && to > s.now {
+		s.record(r.ProcessID, s.now, to)
+		if len(s.resources) > 0 && s.policy.usesPriority() {
+			s.recordInversion(r, s.now, to)
+		}
+		r.carry += (to - s.now) * s.level.speed
+		r.remaining -= r.carry / s.fullSpeed()
+		r.carry %= s.fullSpeed()
+		r.settle()
+		r.ran += to - s.now
+		r.group.cpu += to - s.now
+		if s.policy.shared() {
+			s.ready.fixClass(&r.group.queued)
+			heap.Fix(&s.active, r.group.pos)
+		}
+	}
+	s.now = to
+	if q := s.policy.quantum; r != nil && q > 0 && s.now > s.quantumEnd && s.renewing() {
+		// the quanta that ended meanwhile were renewed, so the current one ends on the
+		// first boundary at or after now.
+		s.quantumEnd += (s.now - s.quantumEnd + q - 1) / q * q
+	}
+	switch {
+	case r == nil:
+	case s.cycles(r) <= 0:
+		// the last tick may not have been needed in full.
+		r.remaining, r.carry = 0, 0
+		r.settle()
+		s.unlockAll(r)
+		r.exit = s.now
+		r.group.active--
+		if r.group.active == 0 && s.policy.shared() {
+			heap.Remove(&s.active, r.group.pos)
+		}
+		s.finished++
+		s.running = nil
+		s.free += r.Memory
+		if s.policy.predict {
+			s.learn(r)
+		}
+		s.emit(EventComplete, r, "burst finished")
+	case s.policy.quantum > 0 && s.now >= s.quantumEnd:
+		s.expired = r
+		s.running = nil
+	}
+}
+
+func (s *Simulation) decide() {
+	for s.next < len(s.arrivals) && s.arrivals[s.next].ArrivalTime <= s.now {
+		t := s.arrivals[s.next]
+		s.next++
+		s.emit(EventArrival, t, "")
+		if s.policy.predict {
+			s.predict(t)
+		}
+		s.activate(t.group)
+		if s.policy.memory > 0 {
+			t.since = s.now
+			s.pending = append(s.pending, t)
+		} else {
+			s.enqueue(t, "arrived")
+		}
+	}
+	s.admit()
+	if t := s.expired; t != nil {
+		s.expired = nil
+		switch {
+		case !s.lock(t):
+		case s.ready.Len() == 0:
+			// nothing else to run, so the quantum is simply renewed.
+			s.running = t
+			s.quantumEnd = s.now + s.policy.quantum
+		default:
+			s.emit(EventPreempt, t, "quantum expired")
+			s.enqueue(t, "quantum expired")
+		}
+	}
+	if r := s.running; r != nil {
+		s.lock(r)
+	}
+	if r := s.running; r != nil && s.policy.preemptive && s.ready.Len() > 0 &&
+		s.policy.compare(s.ready.peek(), r) < 0 {
+		s.running = nil
+		s.emit(EventPreempt, r, s.policy.preemption(s.ready.peek(), r, s.now))
+		s.enqueue(r, "preempted")
+	}
+	// a dispatched task may block straight away, on the resource its burst starts by locking.
+	for s.running == nil && s.ready.Len() > 0 {
+		t := s.ready.pop()
+		s.running = t
+		s.quantumEnd = s.now + s.policy.quantum
+		s.emit(EventDispatch, t, s.policy.describe(t))
+		s.lock(t)
+	}
+	if s.policy.dvfs {
+		s.scale()
+	}
+}
+
+// account adds the energy used over the next elapsed ticks, while the CPU has processes to run.
+func (s *Simulation) account(elapsed int64, busy bool) {
+	switch {
+	case s.policy.power == nil:
+	case busy:
+		s.energy += float64(elapsed) * s.level.power
+	case s.next > 0 && !s.done():
+		s.energy += float64(elapsed) * s.policy.power.idle
+	}
+}
+
+// scale sets the CPU to the slowest level at which the running process and then every other
+// unfinished process, in deadline order, still all meet their deadlines, or to the fastest
+// level if none does. Processes yet to arrive count too, so that slowing down now can't leave
+// too little time for them.
+func (s *Simulation) scale() {
+	if prev := s.scaled; prev != s.running {
+		if prev != nil && s.cycles(prev) > 0 {
+			s.deadlines.queue(prev, s.cycles(prev))
+		}
+		if s.running != nil {
+			s.deadlines.dequeue(s.running)
+		}
+		s.scaled = s.running
+	}
+	var work int64
+	if s.running != nil {
+		work = s.cycles(s.running)
+	}
+	level := s.policy.power.full()
+	for i, l := range s.policy.power.levels {
+		if s.running != nil && s.now+(work+l.speed-1)/l.speed > s.running.deadline {
+			continue
+		}
+		if s.deadlines.meet(i, l.speed, s.now, work) {
+			level = l
+			break
+		}
+	}
+	if level != s.level {
+		s.level = level
+		if s.running != nil {
+			s.emit(EventSpeed, s.running, s.policy.power.describe(level)+", as deadlines allow")
+		}
+	}
+}
+
+// activate counts an arrival in g. Under a policy with shared keys, a group that had no active
+// tasks catches up to the least CPU time received by the active groups, so that it can't claim
+// CPU for the time it was idle.
+func (s *Simulation) activate(g *group) {
+	if g.active == 0 && s.policy.shared() {
+		if len(s.active) > 0 {
+			g.cpu = max(g.cpu, s.active[0].cpu)
+		}
+		heap.Push(&s.active, g)
+	}
+	g.active++
+}
+
+func (h groupHeap) Len() int           { return len(h) }
+func (h groupHeap) Less(i, j int) bool { return h[i].cpu < h[j].cpu }
+func (h groupHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos, h[j].pos = i, j
+}
+func (h *groupHeap) Push(x any) {
+	g := x.(*group)
+	g.pos = len(*h)
+	*h = append(*h, g)
+}
+func (h *groupHeap) Pop() any {
+	old := *h
+	g := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return g
+}
+
+// admit brings waiting processes into memory in FIFO order, swapped out processes first,
+// until one doesn't fit.
+func (s *Simulation) admit() {
+	for len(s.swapped)+len(s.pending) > 0 {
+		queue, reason := &s.swapped, "swapped in"
+		if len(s.swapped) == 0 {
+			queue, reason = &s.pending, "admitted"
+		}
+		t := (*queue)[0]
+		if t.Memory > s.free && !(s.policy.swap && s.swapOut(t)) {
+			return
+		}
+		*queue = (*queue)[1:]
+		s.free -= t.Memory
+		t.admission += s.now - t.since
+		s.emit(EventAdmit, t, fmt.Sprintf("%s, %d of %d memory free", reason, s.free, s.policy.memory))
+		s.enqueue(t, reason)
+	}
+}
+
+// swapOut frees memory for t by swapping out the ready processes that would be dispatched
+// after it, last first, returning false without swapping any if they don't free enough.
+func (s *Simulation) swapOut(t *task) bool {
+	var victims []*task
+	freed := s.free
+	queue := s.ready.sorted()
+	for i := len(queue) - 1; i >= 0; i-- {
+		v := queue[i]
+		if freed >= t.Memory || s.policy.compare(t, v) >= 0 {
+			break
+		}
+		if s.holds(v) {
+			// the tasks blocked on it may be all that's in memory.
+			continue
+		}
+		victims = append(victims, v)
+		freed += v.Memory
+	}
+	if freed < t.Memory {
+		return false
+	}
+	for _, v := range victims {
+		s.ready.remove(v)
+		s.free += v.Memory
+		v.since = s.now
+		s.swapped = append(s.swapped, v)
+		s.swaps++
+		s.emit(EventSwapOut, v, "making room for "+t.ProcessID)
+	}
+	return true
+}
+
+func (s *Simulation) enqueue(t *task, reason string) {
+	t.seq = s.seq
+	s.seq++
+	s.ready.push(t)
+	s.emit(EventEnqueue, t, reason)
+}
+
+func (s *Simulation) record(pid string, start, stop int64) {
+	var speed float64
+	if s.policy.power != nil {
+		speed = float64(s.level.speed) / float64(s.fullSpeed())
+	}
+	if n := len(s.gantt); n > 0 && s.gantt[n-1].PID == pid && s.gantt[n-1].Stop == start && s.gantt[n-1].Speed == speed {
+		s.gantt[n-1].Stop = stop
+		return
+	}
+	if s.stream != nil && len(s.gantt) > 0 {
+		s.stream(s.gantt[0])
+		s.gantt = s.gantt[:0]
+	}
+	s.gantt = append(s.gantt, TimeSlice{PID: pid, Start: start, Stop: stop, Speed: speed})
+}
+
+func (s *Simulation) emit(kind EventKind, t *task, reason string) {
+	if s.observe != nil {
+		s.observe(Event{Time: s.now, Kind: kind, PID: t.ProcessID, Remaining: t.remaining, Reason: reason})
+	}
+}
+
+// Result summarizes a finished simulation.
+func (s *Simulation) Result(title string) Result {
+	rows := make([]ProcessResult, len(s.tasks))
+	for i, t := range s.tasks {
+		turnaround := t.exit - t.ArrivalTime
+		rows[i] = ProcessResult{
+			Process:    t.Process,
+			Predicted:  t.predicted,
+			Admission:  t.admission,
+			Blocked:    t.blocked,
+			Wait:       turnaround - t.ran - t.admission - t.blocked,
+			Turnaround: turnaround,
+			Exit:       t.exit,
+		}
+	}
+	res := Summarize(title, s.gantt, rows)
+	res.MemoryLimit, res.Swaps, res.Timescale = s.policy.memory, s.swaps, s.policy.clock
+	res.Blocked, res.Inversions = s.lockWaits, s.inversions
+	if s.policy.power != nil && len(s.arrivals) > 0 {
+		res.Energy = s.energy
+		res.EDP = s.energy * float64(s.now-s.arrivals[0].ArrivalTime)
+	}
+	if s.policy.predict {
+		res.Prediction = s.predictionStats()
+	}
+	if slices.ContainsFunc(s.tasks, func(t *task) bool { return t.Group != "" }) {
+		res.Groups = groupShares(rows, s.gantt)
+	}
+
+	return res
+}
+
+//endregion
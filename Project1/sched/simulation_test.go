@@ -0,0 +1,526 @@
+package sched
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var exampleProcesses = []Process{
+	{ProcessID: "1", BurstDuration: 10, ArrivalTime: 0, Priority: 2},
+	{ProcessID: "2", BurstDuration: 1, ArrivalTime: 1, Priority: 1},
+	{ProcessID: "3", BurstDuration: 2, ArrivalTime: 2, Priority: 3},
+	{ProcessID: "4", BurstDuration: 1, ArrivalTime: 3, Priority: 4},
+	{ProcessID: "5", BurstDuration: 5, ArrivalTime: 4, Priority: 2},
+}
+
+func Test_simulate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		scheduler scheduler
+		processes []Process
+		wantGantt []TimeSlice
+		wantExits []int64
+	}{
+		{
+			name:      "fcfs",
+			scheduler: fcfs,
+			processes: exampleProcesses,
+			wantGantt: []TimeSlice{
+				{PID: "1", Start: 0, Stop: 10},
+				{PID: "2", Start: 10, Stop: 11},
+				{PID: "3", Start: 11, Stop: 13},
+				{PID: "4", Start: 13, Stop: 14},
+				{PID: "5", Start: 14, Stop: 19},
+			},
+			wantExits: []int64{10, 11, 13, 14, 19},
+		},
+		{
+			name:      "fcfs idle CPU",
+			scheduler: fcfs,
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2, ArrivalTime: 1},
+				{ProcessID: "B", BurstDuration: 2, ArrivalTime: 5},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 1, Stop: 3},
+				{PID: "B", Start: 5, Stop: 7},
+			},
+			wantExits: []int64{3, 7},
+		},
+		{
+			name:      "sjf preempts for shorter jobs",
+			scheduler: sjf,
+			processes: exampleProcesses,
+			wantGantt: []TimeSlice{
+				{PID: "1", Start: 0, Stop: 1},
+				{PID: "2", Start: 1, Stop: 2},
+				{PID: "3", Start: 2, Stop: 4},
+				{PID: "4", Start: 4, Stop: 5},
+				{PID: "5", Start: 5, Stop: 10},
+				{PID: "1", Start: 10, Stop: 19},
+			},
+			wantExits: []int64{19, 2, 4, 5, 10},
+		},
+		{
+			name:      "priority breaks ties by shortest job",
+			scheduler: sjfp,
+			processes: exampleProcesses,
+			wantGantt: []TimeSlice{
+				{PID: "1", Start: 0, Stop: 1},
+				{PID: "2", Start: 1, Stop: 2},
+				{PID: "1", Start: 2, Stop: 4},
+				{PID: "5", Start: 4, Stop: 9},
+				{PID: "1", Start: 9, Stop: 16},
+				{PID: "3", Start: 16, Stop: 18},
+				{PID: "4", Start: 18, Stop: 19},
+			},
+			wantExits: []int64{16, 2, 18, 19, 9},
+		},
+		{
+			name:      "rr enqueues arrivals before the expired process",
+			scheduler: rr,
+			processes: exampleProcesses[:4],
+			wantGantt: []TimeSlice{
+				{PID: "1", Start: 0, Stop: 1},
+				{PID: "2", Start: 1, Stop: 2},
+				{PID: "1", Start: 2, Stop: 3},
+				{PID: "3", Start: 3, Stop: 4},
+				{PID: "4", Start: 4, Stop: 5},
+				{PID: "1", Start: 5, Stop: 6},
+				{PID: "3", Start: 6, Stop: 7},
+				{PID: "1", Start: 7, Stop: 14},
+			},
+			wantExits: []int64{14, 2, 7, 5},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := Simulate(tt.scheduler.policy(), tt.processes, nil).Result(tt.name)
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			exits := make([]int64, len(res.Rows))
+			for i := range res.Rows {
+				exits[i] = res.Rows[i].Exit
+			}
+			if diff := cmp.Diff(tt.wantExits, exits); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func Test_simulate_memory(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		policy        Policy
+		processes     []Process
+		wantGantt     []TimeSlice
+		wantAdmission []int64
+		wantWait      []int64
+		wantSwaps     int
+	}{
+		{
+			name:   "admission is FIFO",
+			policy: Policy{keys: []sortKey{byArrival}, memory: 10},
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 3, ArrivalTime: 0, Memory: 6},
+				{ProcessID: "B", BurstDuration: 2, ArrivalTime: 1, Memory: 6},
+				{ProcessID: "C", BurstDuration: 1, ArrivalTime: 1, Memory: 4},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 0, Stop: 3},
+				{PID: "B", Start: 3, Stop: 5},
+				{PID: "C", Start: 5, Stop: 6},
+			},
+			wantAdmission: []int64{0, 2, 2},
+			wantWait:      []int64{0, 0, 2},
+		},
+		{
+			name:   "swapping admits a shorter job",
+			policy: Policy{keys: []sortKey{byRemaining}, preemptive: true, memory: 10, swap: true},
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 6, ArrivalTime: 0, Memory: 5},
+				{ProcessID: "B", BurstDuration: 4, ArrivalTime: 0, Memory: 5},
+				{ProcessID: "C", BurstDuration: 1, ArrivalTime: 1, Memory: 5},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "B", Start: 0, Stop: 1},
+				{PID: "C", Start: 1, Stop: 2},
+				{PID: "B", Start: 2, Stop: 5},
+				{PID: "A", Start: 5, Stop: 11},
+			},
+			wantAdmission: []int64{1, 0, 0},
+			wantWait:      []int64{4, 1, 0},
+			wantSwaps:     1,
+		},
+		{
+			name:   "round robin never swaps",
+			policy: Policy{quantum: 1, memory: 10, swap: true},
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2, ArrivalTime: 0, Memory: 5},
+				{ProcessID: "B", BurstDuration: 2, ArrivalTime: 0, Memory: 5},
+				{ProcessID: "C", BurstDuration: 1, ArrivalTime: 1, Memory: 5},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 0, Stop: 1},
+				{PID: "B", Start: 1, Stop: 2},
+				{PID: "A", Start: 2, Stop: 3},
+				{PID: "B", Start: 3, Stop: 4},
+				{PID: "C", Start: 4, Stop: 5},
+			},
+			wantAdmission: []int64{0, 0, 2},
+			wantWait:      []int64{1, 2, 1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := Simulate(tt.policy, tt.processes, nil).Result(tt.name)
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			admission := make([]int64, len(res.Rows))
+			wait := make([]int64, len(res.Rows))
+			for i := range res.Rows {
+				admission[i], wait[i] = res.Rows[i].Admission, res.Rows[i].Wait
+			}
+			if diff := cmp.Diff(tt.wantAdmission, admission); diff != "" {
+				t.Errorf("admission: %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantWait, wait); diff != "" {
+				t.Errorf("wait: %s", diff)
+			}
+			if res.Swaps != tt.wantSwaps {
+				t.Errorf("swaps = %d, want %d", res.Swaps, tt.wantSwaps)
+			}
+			if err := CheckInvariants(tt.processes, res, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_simulate_group(t *testing.T) {
+	t.Parallel()
+	alg, err := LookupAlgorithm("group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		processes  []Process
+		wantGantt  []TimeSlice
+		wantGroups []GroupShare
+	}{
+		{
+			name: "shares between groups, then within",
+			processes: []Process{
+				{ProcessID: "A1", BurstDuration: 3, Group: "A"},
+				{ProcessID: "A2", BurstDuration: 3, Group: "A"},
+				{ProcessID: "A3", BurstDuration: 3, Group: "A"},
+				{ProcessID: "B1", BurstDuration: 3, Group: "B"},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A1", Start: 0, Stop: 1},
+				{PID: "B1", Start: 1, Stop: 2},
+				{PID: "A2", Start: 2, Stop: 3},
+				{PID: "B1", Start: 3, Stop: 4},
+				{PID: "A3", Start: 4, Stop: 5},
+				{PID: "B1", Start: 5, Stop: 6},
+				{PID: "A1", Start: 6, Stop: 7},
+				{PID: "A2", Start: 7, Stop: 8},
+				{PID: "A3", Start: 8, Stop: 9},
+				{PID: "A1", Start: 9, Stop: 10},
+				{PID: "A2", Start: 10, Stop: 11},
+				{PID: "A3", Start: 11, Stop: 12},
+			},
+			wantGroups: []GroupShare{
+				{Group: "A", Processes: 3, CPU: 9, Contended: 3, Share: 0.5},
+				{Group: "B", Processes: 1, CPU: 3, Contended: 3, Share: 0.5},
+			},
+		},
+		{
+			name: "late group catches up instead of claiming idle time",
+			processes: []Process{
+				{ProcessID: "A1", BurstDuration: 4, Group: "A"},
+				{ProcessID: "B1", BurstDuration: 2, ArrivalTime: 2, Group: "B"},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A1", Start: 0, Stop: 2},
+				{PID: "B1", Start: 2, Stop: 3},
+				{PID: "A1", Start: 3, Stop: 4},
+				{PID: "B1", Start: 4, Stop: 5},
+				{PID: "A1", Start: 5, Stop: 6},
+			},
+			wantGroups: []GroupShare{
+				{Group: "A", Processes: 1, CPU: 4, Contended: 1, Share: 1.0 / 3},
+				{Group: "B", Processes: 1, CPU: 2, Contended: 2, Share: 2.0 / 3},
+			},
+		},
+		{
+			name: "a process without a group isn't in the group named like it",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 2},
+				{ProcessID: "B1", BurstDuration: 2, Group: "A"},
+				{ProcessID: "B2", BurstDuration: 2, Group: "A"},
+			},
+			wantGantt: []TimeSlice{
+				{PID: "A", Start: 0, Stop: 1},
+				{PID: "B1", Start: 1, Stop: 2},
+				{PID: "B2", Start: 2, Stop: 3},
+				{PID: "A", Start: 3, Stop: 4},
+				{PID: "B1", Start: 4, Stop: 5},
+				{PID: "B2", Start: 5, Stop: 6},
+			},
+			wantGroups: []GroupShare{
+				{Group: "A", Processes: 1, CPU: 2, Contended: 2, Share: 0.5},
+				{Group: "A", Processes: 2, CPU: 4, Contended: 2, Share: 0.5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := Simulate(alg.policy(alg.Defaults()), tt.processes, nil).Result(alg.Title)
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			if diff := cmp.Diff(tt.wantGroups, res.Groups); diff != "" {
+				t.Errorf(diff)
+			}
+			if err := CheckInvariants(tt.processes, res, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStepSchedule(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		commands string
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:     "tick then event",
+			commands: "\ne\nq\n",
+			want: []string{
+				"Time 0\n  arrival  1\n",
+				"Time 1\n  arrival  2\n",
+				"preempt  1: shorter job arrived",
+				"Time 2\n  complete 2: burst finished\n",
+				"Ready: [1 (remaining 9)]",
+			},
+			wantNot: []string{"Schedule table"},
+		},
+		{
+			name:     "out of input runs to completion",
+			commands: "",
+			want:     []string{"Schedule table", "Average wait: 2.20"},
+			wantNot:  []string{"Time 1\n"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var w bytes.Buffer
+			StepSchedule(strings.NewReader(tt.commands), &w, "SJF", sjf.policy(), exampleProcesses, nil)
+			for _, s := range tt.want {
+				if !strings.Contains(w.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, w.String())
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(w.String(), s) {
+					t.Errorf("output unexpectedly contains %q:\n%s", s, w.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_traceEvents(t *testing.T) {
+	t.Parallel()
+	var w bytes.Buffer
+	Simulate(rr.policy(), []Process{
+		{ProcessID: "A", BurstDuration: 2, ArrivalTime: 0},
+		{ProcessID: "B", BurstDuration: 1, ArrivalTime: 1},
+	}, TraceEvents(&w))
+
+	want := `{"time":0,"kind":"arrival","pid":"A","remaining":2}
+{"time":0,"kind":"enqueue","pid":"A","remaining":2,"reason":"arrived"}
+{"time":0,"kind":"dispatch","pid":"A","remaining":2,"reason":"first in ready queue (FIFO)"}
+{"time":1,"kind":"arrival","pid":"B","remaining":1}
+{"time":1,"kind":"enqueue","pid":"B","remaining":1,"reason":"arrived"}
+{"time":1,"kind":"preempt","pid":"A","remaining":1,"reason":"quantum expired"}
+{"time":1,"kind":"enqueue","pid":"A","remaining":1,"reason":"quantum expired"}
+{"time":1,"kind":"dispatch","pid":"B","remaining":1,"reason":"first in ready queue (FIFO)"}
+{"time":2,"kind":"complete","pid":"B","remaining":0,"reason":"burst finished"}
+{"time":2,"kind":"dispatch","pid":"A","remaining":1,"reason":"first in ready queue (FIFO)"}
+{"time":3,"kind":"complete","pid":"A","remaining":0,"reason":"burst finished"}
+`
+	if diff := cmp.Diff(want, w.String()); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// largeWorkload generates n processes in 8 groups, arriving a little faster than they can be
+// run, so that the ready queue keeps growing.
+func largeWorkload(n int) []Process {
+	r := rand.New(rand.NewPCG(4900, uint64(n)))
+	return GenerateWorkload(r, WorkloadShape{Count: n, MaxArrival: int64(4 * n), MaxBurst: 8, MaxPriority: 8, MaxMemory: 8, Groups: 8})
+}
+
+// BenchmarkSimulate runs every algorithm over generated workloads of growing size, which
+// should take O(n log n): go test -run - -bench Simulate ./sched
+func BenchmarkSimulate(b *testing.B) {
+	power, err := ParsePowerModel("default")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, n := range []int{1_000, 10_000, 100_000} {
+		processes := largeWorkload(n)
+		for _, alg := range Algorithms() {
+			alg := alg
+			b.Run(fmt.Sprintf("%s/%d", alg.Name, n), func(b *testing.B) {
+				p := alg.Policy(alg.Defaults(), Machine{Power: power})
+				for i := 0; i < b.N; i++ {
+					Simulate(p, processes, nil)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSimulate_longBurst runs a burst far longer than the quantum, mostly alone, which
+// should take time in the processes rather than in the quanta: go test -run - -bench longBurst ./sched
+func BenchmarkSimulate_longBurst(b *testing.B) {
+	rr, err := LookupAlgorithm("rr")
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := rr.Policy(map[string]int64{"quantum": 1}, Machine{})
+	processes := []Process{
+		{ProcessID: "A", BurstDuration: 10_000_000},
+		{ProcessID: "B", BurstDuration: 10, ArrivalTime: 5_000_000},
+	}
+	for i := 0; i < b.N; i++ {
+		Simulate(p, processes, nil)
+	}
+}
+
+func Test_readyQueue(t *testing.T) {
+	t.Parallel()
+	p := Policy{keys: []sortKey{byGroupCPU, byRemaining}}
+	groups := []*group{{}, {}, {}}
+	for _, g := range groups {
+		g.queued = class{heap: taskHeap{policy: p}}
+	}
+	q := newReadyQueue(p)
+	q.classOf = func(t *task) *class { return &t.group.queued }
+	r := rand.New(rand.NewPCG(4900, 2))
+	var seq uint64
+	for i := 0; i < 2000; i++ {
+		switch op := r.IntN(4); {
+		case op == 0 && q.Len() > 0:
+			want := q.sorted()[0]
+			if got := q.pop(); got != want {
+				t.Fatalf("pop() = %s, want %s", got.ProcessID, want.ProcessID)
+			}
+		case op == 1 && q.Len() > 0:
+			// a group runs, so its queued tasks fall back together.
+			g := groups[r.IntN(len(groups))]
+			g.cpu += 1 + r.Int64N(3)
+			q.fixClass(&g.queued)
+		case op == 2 && q.Len() > 0:
+			all := q.all()
+			q.remove(all[r.IntN(len(all))])
+		default:
+			seq++
+			q.push(&task{Process: Process{ProcessID: fmt.Sprint("P", seq)}, remaining: r.Int64N(5), seq: seq,
+				group: groups[r.IntN(len(groups))]})
+		}
+		if q.Len() > 0 && q.peek() != q.sorted()[0] {
+			t.Fatalf("peek() = %s, want %s", q.peek().ProcessID, q.sorted()[0].ProcessID)
+		}
+	}
+}
+
+func Test_preemptionReasons(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		algorithm string
+		processes []Process
+		want      []string
+	}{
+		{
+			name:      "shorter job of equal priority",
+			algorithm: "sjfp",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5, Priority: 1},
+				{ProcessID: "B", ArrivalTime: 1, BurstDuration: 1, Priority: 1},
+			},
+			want: []string{"A: shorter job arrived"},
+		},
+		{
+			name:      "higher priority",
+			algorithm: "sjfp",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5, Priority: 2},
+				{ProcessID: "B", ArrivalTime: 1, BurstDuration: 9, Priority: 1},
+			},
+			want: []string{"A: higher priority arrived"},
+		},
+		{
+			name:      "descending expression key",
+			algorithm: "burst desc; preemptive",
+			processes: []Process{
+				{ProcessID: "A", BurstDuration: 5},
+				{ProcessID: "B", ArrivalTime: 1, BurstDuration: 9},
+			},
+			want: []string{"A: longer burst arrived"},
+		},
+		{
+			name:      "inherited priority dropped",
+			algorithm: "sjfp-inherit",
+			processes: inversion,
+			// H, blocked on L's resource since it arrived, takes over when L gives it back.
+			want: []string{"L: higher priority arrived", "M: higher priority arrived", "L: higher priority ready"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			alg, err := LookupAlgorithm(tt.algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			Simulate(alg.Policy(alg.Defaults(), Machine{}), tt.processes, func(e Event) {
+				if e.Kind == EventPreempt {
+					got = append(got, e.PID+": "+e.Reason)
+				}
+			})
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
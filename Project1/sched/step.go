@@ -0,0 +1,123 @@
+package sched
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// StepSchedule runs a schedule interactively, pausing after every tick (or event) to show
+// the clock, the running process, the ready queue and the Gantt chart drawn so far, along with
+// the decisions that were made and why.
+// Commands are read a line at a time from in:
+// • <enter> or "t" advances one tick
+// • "e" advances to the next event
+// • "c" runs to completion without pausing
+// • "q" quits
+// Running out of input is the same as "c".
+// observe, if not nil, is also called with every event.
+func StepSchedule(in io.Reader, w io.Writer, title string, p Policy, processes []Process, observe func(Event)) {
+	var events []Event
+	sim := newSimulation(p, processes, func(e Event) {
+		events = append(events, e)
+		if observe != nil {
+			observe(e)
+		}
+	})
+	cmds := bufio.NewScanner(in)
+	interactive, byEvent := true, false
+
+	OutputTitle(w, title)
+	outputStep(w, sim, events)
+	events = events[:0]
+	for !sim.done() {
+		if interactive {
+			_, _ = fmt.Fprint(w, "[enter] tick, (e)vent, (c)ontinue, (q)uit: ")
+			if !cmds.Scan() {
+				interactive = false
+			}
+			switch strings.TrimSpace(cmds.Text()) {
+			case "", "t":
+				byEvent = false
+			case "e":
+				byEvent = true
+			case "c":
+				interactive = false
+			case "q":
+				return
+			}
+		}
+
+		until := sim.now + 1
+		if byEvent || !interactive {
+			until = math.MaxInt64
+		}
+		sim.step(until)
+		if interactive {
+			outputStep(w, sim, events)
+		}
+		events = events[:0]
+	}
+	_, _ = fmt.Fprintln(w)
+	OutputResult(w, sim.Result(title))
+}
+
+func outputStep(w io.Writer, sim *Simulation, events []Event) {
+	ts := sim.policy.clock
+	_, _ = fmt.Fprintf(w, "\nTime %s\n", ts.FormatTime(sim.now))
+	for _, e := range events {
+		_, _ = fmt.Fprintf(w, "  %-8s %s", e.Kind, e.PID)
+		if e.Reason != "" {
+			_, _ = fmt.Fprintf(w, ": %s", e.Reason)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	running := "idle"
+	if r := sim.running; r != nil {
+		running = fmt.Sprintf("%s (remaining %s)", r.ProcessID, ts.FormatTime(r.remaining))
+		if sim.policy.quantum > 0 {
+			running += ", quantum ends at " + ts.FormatTime(sim.quantumEnd)
+		}
+		if sim.policy.power != nil {
+			running += ", " + sim.policy.power.describe(sim.level)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "Running: %s\n", running)
+
+	queue := sim.ready.sorted()
+	ready := make([]string, len(queue))
+	for i, t := range queue {
+		ready[i] = fmt.Sprintf("%s (remaining %s)", t.ProcessID, ts.FormatTime(t.remaining))
+	}
+	_, _ = fmt.Fprintf(w, "Ready: [%s]\n", strings.Join(ready, ", "))
+	if sim.policy.memory > 0 {
+		var waiting []string
+		for _, t := range slices.Concat(sim.swapped, sim.pending) {
+			waiting = append(waiting, fmt.Sprintf("%s (memory %d)", t.ProcessID, t.Memory))
+		}
+		_, _ = fmt.Fprintf(w, "Waiting for memory: [%s], %d of %d free\n", strings.Join(waiting, ", "), sim.free, sim.policy.memory)
+	}
+	if len(sim.resources) > 0 {
+		var held, blocked []string
+		for _, r := range sim.resources {
+			if r.holder != nil {
+				held = append(held, fmt.Sprintf("%s by %s (priority %d)", r.name, r.holder.ProcessID, r.holder.prio))
+			}
+			for _, t := range r.waiters {
+				blocked = append(blocked, fmt.Sprintf("%s on %s", t.ProcessID, r.name))
+			}
+		}
+		_, _ = fmt.Fprintf(w, "Held: [%s]\n", strings.Join(held, ", "))
+		_, _ = fmt.Fprintf(w, "Blocked: [%s]\n", strings.Join(blocked, ", "))
+	}
+
+	if len(sim.gantt) > 0 {
+		outputGantt(w, slices.Clone(sim.gantt), ts)
+	} else {
+		_, _ = fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,57 @@
+package sched
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+)
+
+// StreamSchedule runs processes under p like Simulate, but writes the schedule to w as it is
+// made, for workloads too large to chart: the Gantt schedule is written as CSV, one slice per
+// line as it ends, without being kept, then the schedule table as CSV, and the averages and
+// totals as OutputResult writes them, less the group shares, which need the whole chart.
+func StreamSchedule(w io.Writer, title string, p Policy, processes []Process, observe func(Event)) error {
+	bw := bufio.NewWriter(w)
+	records := csv.NewWriter(bw)
+	ts := p.clock
+
+	OutputTitle(bw, title)
+	_, _ = fmt.Fprintln(bw, "Gantt schedule")
+	_ = records.Write([]string{"Start", "Stop", "ID"})
+	var last *int64
+	write := func(slice TimeSlice) {
+		if last != nil && slice.Start > *last {
+			_ = records.Write([]string{ts.FormatTime(*last), ts.FormatTime(slice.Start), "-"})
+		}
+		_ = records.Write([]string{ts.FormatTime(slice.Start), ts.FormatTime(slice.Stop), slice.PID})
+		last = &slice.Stop
+	}
+
+	s := newSimulation(p, processes, observe)
+	s.stream = write
+	for !s.done() {
+		s.step(math.MaxInt64)
+	}
+	for _, slice := range s.gantt {
+		write(slice)
+	}
+	s.gantt = nil
+
+	res := s.Result(title)
+	res.Groups = nil
+	header, rows := scheduleTable(res)
+	records.Flush()
+	_, _ = fmt.Fprintln(bw)
+	_, _ = fmt.Fprintln(bw, "Schedule table")
+	_ = records.Write(header)
+	_ = records.WriteAll(rows)
+	outputAverages(bw, ts, res.AverageWait, res.AverageTurnaround, res.Throughput)
+	outputTotals(bw, res)
+
+	if err := records.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,98 @@
+package sched
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamSchedule(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: "A", ArrivalTime: 2, BurstDuration: 3, Priority: 3},
+		{ProcessID: "B", ArrivalTime: 4, BurstDuration: 2, Priority: 1},
+		{ProcessID: "C", ArrivalTime: 12, BurstDuration: 4, Priority: 2},
+		{ProcessID: "D", ArrivalTime: 13, BurstDuration: 1, Priority: 1},
+	}
+	var w bytes.Buffer
+	if err := StreamSchedule(&w, "Round-robin", rr.policy(), processes, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `----------------------
+      Round-robin
+----------------------
+Gantt schedule
+Start,Stop,ID
+2,4,A
+4,5,B
+5,6,A
+6,7,B
+7,12,-
+12,13,C
+13,14,D
+14,17,C
+
+Schedule table
+ID,Priority,Burst,Arrival,Wait,Turnaround,Exit
+A,3,3,2,1,4,6
+B,1,2,4,1,3,7
+C,2,4,12,1,5,17
+D,1,1,13,0,1,14
+
+Average wait: 0.75
+Average turnaround: 3.25
+Throughput: 0.24
+`
+	if diff := cmp.Diff(want, w.String()); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// Test_streamSchedule_workloads checks the streamed schedule of every workload is the one
+// Simulate charts.
+func Test_streamSchedule_workloads(t *testing.T) {
+	t.Parallel()
+	workloads, err := filepath.Glob(filepath.Join("testdata", "workloads", "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workload := range workloads {
+		workload := workload
+		for _, alg := range Algorithms() {
+			alg := alg
+			t.Run(filepath.Base(workload)+"/"+alg.Name, func(t *testing.T) {
+				t.Parallel()
+				processes := loadWorkload(t, workload)
+				p := alg.Policy(alg.Defaults(), Machine{Power: &PowerModel{levels: []powerLevel{{speed: 1, power: 1}, {speed: 2, power: 3}}}})
+				var w bytes.Buffer
+				if err := StreamSchedule(&w, alg.Title, p, processes, nil); err != nil {
+					t.Fatal(err)
+				}
+				_, chart, _ := strings.Cut(w.String(), "Gantt schedule\n")
+				chart, _, _ = strings.Cut(chart, "\n\n")
+				records, err := csv.NewReader(strings.NewReader(chart)).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []TimeSlice
+				for _, r := range records[1:] {
+					if r[2] == "-" {
+						continue
+					}
+					start, _ := strconv.ParseInt(r[0], 10, 64)
+					stop, _ := strconv.ParseInt(r[1], 10, 64)
+					got = append(got, TimeSlice{PID: r[2], Start: start, Stop: stop})
+				}
+				want := Simulate(p, processes, nil).Result(alg.Title).Gantt
+				if !sameSlices(got, want) {
+					t.Errorf("streamed %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package sched
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type (
+	// IOBurst is a blocking call a thread makes once it has received At CPU time, which blocks
+	// it for Duration.
+	IOBurst struct {
+		At       int64 `json:"at"`
+		Duration int64 `json:"duration"`
+	}
+
+	// ThreadModel maps the user threads of a process onto the kernel threads the CPU is
+	// scheduled between:
+	// • 1:1 gives every user thread its own kernel thread
+	// • N:1 runs all the user threads of a process on a single kernel thread
+	// • M:N runs them on up to KernelThreads kernel threads
+	ThreadModel struct {
+		Name          string
+		KernelThreads int // per process, for M:N
+	}
+
+	// ThreadResult is a schedule of user threads, and the turnaround of the processes they
+	// belong to.
+	ThreadResult struct {
+		Result
+		Model                    string          `json:"model"`
+		Processes                []ThreadProcess `json:"processes"`
+		AverageProcessTurnaround float64         `json:"averageProcessTurnaround"`
+	}
+
+	// ThreadProcess is a process of user threads, which exits with its last thread.
+	ThreadProcess struct {
+		Process       string `json:"process"`
+		Threads       int    `json:"threads"`
+		KernelThreads int    `json:"kernelThreads"`
+		Arrival       int64  `json:"arrival"`
+		Exit          int64  `json:"exit"`
+		Turnaround    int64  `json:"turnaround"`
+	}
+
+	// threadProcess is the user-level scheduler of a process.
+	threadProcess struct {
+		ThreadProcess
+		bound int        // user threads holding a kernel thread
+		ready readyQueue // arrived user threads waiting for a kernel thread
+	}
+
+	// thread is the simulation's view of a user thread.
+	thread struct {
+		*task
+		process *threadProcess
+		io      int   // next entry in IO
+		until   int64 // when its blocking call returns, while blocked
+	}
+)
+
+// ThreadModels are the models threads can be mapped onto kernel threads with.
+var ThreadModels = []string{"1:1", "N:1", "M:N"}
+
+func (tm ThreadModel) Validate() error {
+	switch {
+	case !slices.Contains(ThreadModels, tm.Name):
+		return fmt.Errorf("%w: unknown thread model %q, expected one of %v", ErrInvalidArgs, tm.Name, ThreadModels)
+	case tm.Name == "M:N" && tm.KernelThreads < 1:
+		return fmt.Errorf("%w: M:N needs at least one kernel thread per process", ErrInvalidArgs)
+	}
+	return nil
+}
+
+func (tm ThreadModel) String() string {
+	if tm.Name == "M:N" {
+		return fmt.Sprintf("M:N (%d kernel threads)", tm.KernelThreads)
+	}
+	return tm.Name
+}
+
+// kernelThreads returns the kernel threads a process of n user threads runs on.
+func (tm ThreadModel) kernelThreads(n int) int {
+	switch tm.Name {
+	case "1:1":
+		return n
+	case "N:1":
+		return 1
+	default:
+		return min(tm.KernelThreads, n)
+	}
+}
+
+// ParseIO parses blocking calls written as <at>:<duration>, separated by semicolons, with
+// times written as decimal times of ts.
+func ParseIO(spec string, ts Timescale) ([]IOBurst, error) {
+	var bursts []IOBurst
+	for _, entry := range strings.Split(spec, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		at, duration, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: blocking call %q isn't <at>:<duration>", ErrInvalidArgs, entry)
+		}
+		var (
+			b   IOBurst
+			err error
+		)
+		if b.At, err = ts.ParseTime(at); err != nil {
+			return nil, fmt.Errorf("%w: blocking call %q", err, entry)
+		}
+		if b.Duration, err = ts.ParseTime(duration); err != nil {
+			return nil, fmt.Errorf("%w: blocking call %q", err, entry)
+		}
+		bursts = append(bursts, b)
+	}
+	return bursts, nil
+}
+
+// FormatIO writes blocking calls in the format read by ParseIO.
+func FormatIO(bursts []IOBurst, ts Timescale) string {
+	entries := make([]string, len(bursts))
+	for i, b := range bursts {
+		entries[i] = ts.FormatTime(b.At) + ":" + ts.FormatTime(b.Duration)
+	}
+	return strings.Join(entries, ";")
+}
+
+// validateThreads checks the kernel and user policies only order threads, and that every
+// blocking call is made within its thread's burst, in order.
+func validateThreads(kernel, user Policy, threads []Process) error {
+	for _, p := range []Policy{kernel, user} {
+		if p.predict || p.dvfs || p.protocol != noProtocol {
+			return fmt.Errorf("%w: thread schedulers can only order, preempt and time slice threads", ErrInvalidArgs)
+		}
+	}
+	for _, t := range threads {
+		if len(t.Locks) > 0 || t.Memory != 0 {
+			return fmt.Errorf("%w: thread %s has locks or memory, which aren't simulated for threads", ErrInvalidArgs, t.ProcessID)
+		}
+		last := int64(0)
+		for _, b := range t.IO {
+			if b.At <= last || b.At >= t.BurstDuration || b.Duration < 1 {
+				return fmt.Errorf("%w: thread %s blocks at %d for %d, which isn't after its last call and within its burst of %d",
+					ErrInvalidArgs, t.ProcessID, b.At, b.Duration, t.BurstDuration)
+			}
+			last = b.At
+		}
+	}
+	return nil
+}
+
+// owner returns the process a thread belongs to, a thread without one being a process of its own.
+func (p Process) owner() string {
+	if p.Parent == "" {
+		return p.ProcessID
+	}
+	return p.Parent
+}
+
+// SimulateThreads runs user threads to completion: the user-level scheduler of each process
+// binds its ready threads, in the order of the user policy, to the kernel threads tm gives
+// the process, and the kernel policy schedules the bound threads on the CPU. A bound thread
+// keeps its kernel thread until it exits, even while blocked, so under N:1 a blocking call
+// blocks its whole process. User-level scheduling is cooperative: the user policy only orders
+// threads waiting for a kernel thread.
+func SimulateThreads(kernel, user Policy, tm ThreadModel, threads []Process, title string) (ThreadResult, error) {
+	if err := tm.Validate(); err != nil {
+		return ThreadResult{}, err
+	}
+	if err := validateThreads(kernel, user, threads); err != nil {
+		return ThreadResult{}, err
+	}
+
+	var (
+		processes []*threadProcess
+		byName    = make(map[string]*threadProcess)
+		all       = make([]*thread, len(threads))
+		arrivals  []*thread
+		blocked   []*thread
+		ready     = newReadyQueue(kernel)
+		running   *thread
+		seq       uint64
+		now       int64
+		gantt     []TimeSlice
+		waits     []LockWait
+		exited    int
+	)
+	for i, p := range threads {
+		tp := byName[p.owner()]
+		if tp == nil {
+			tp = &threadProcess{
+				ThreadProcess: ThreadProcess{Process: p.owner(), Arrival: math.MaxInt64},
+				ready:         newReadyQueue(user),
+			}
+			byName[p.owner()] = tp
+			processes = append(processes, tp)
+		}
+		tp.Threads++
+		tp.Arrival = min(tp.Arrival, p.ArrivalTime)
+		all[i] = &thread{
+			task:    &task{Process: p, index: i, remaining: p.BurstDuration, prio: p.Priority},
+			process: tp,
+		}
+	}
+	for _, tp := range processes {
+		tp.KernelThreads = tm.kernelThreads(tp.Threads)
+	}
+	byTask := make(map[*task]*thread, len(all))
+	for _, t := range all {
+		byTask[t.task] = t
+	}
+	arrivals = slices.Clone(all)
+	slices.SortStableFunc(arrivals, func(a, b *thread) int { return cmp.Compare(a.ArrivalTime, b.ArrivalTime) })
+	if len(arrivals) > 0 {
+		now = min(now, arrivals[0].ArrivalTime)
+	}
+	push := func(q *readyQueue, t *thread) {
+		t.seq = seq
+		seq++
+		q.push(t.task)
+	}
+	quantumEnd := int64(0)
+
+	for exited < len(all) {
+		for len(arrivals) > 0 && arrivals[0].ArrivalTime <= now {
+			push(&arrivals[0].process.ready, arrivals[0])
+			arrivals = arrivals[1:]
+		}
+		blocked = slices.DeleteFunc(blocked, func(t *thread) bool {
+			if t.until > now {
+				return false
+			}
+			push(&ready, t)
+			return true
+		})
+		for _, tp := range processes {
+			for tp.bound < tp.KernelThreads && tp.ready.Len() > 0 {
+				tp.bound++
+				push(&ready, byTask[tp.ready.pop()])
+			}
+		}
+		if running != nil && ready.Len() > 0 {
+			expired := kernel.quantum > 0 && now >= quantumEnd
+			if expired || kernel.preemptive && kernel.compare(ready.peek(), running.task) < 0 {
+				push(&ready, running)
+				running = nil
+			}
+		}
+		if running == nil && ready.Len() > 0 {
+			running = byTask[ready.pop()]
+			quantumEnd = now + kernel.quantum
+		} else if running != nil && kernel.quantum > 0 && now >= quantumEnd {
+			// nothing else to run, so the quantum is simply renewed.
+			quantumEnd = now + kernel.quantum
+		}
+
+		if running == nil {
+			// idle until the next arrival or blocking call returns.
+			next := int64(math.MaxInt64)
+			if len(arrivals) > 0 {
+				next = arrivals[0].ArrivalTime
+			}
+			for _, t := range blocked {
+				next = min(next, t.until)
+			}
+			now = next
+			continue
+		}
+
+		// run to the next decision: a quantum expiring, the thread blocking or exiting, or an
+		// arrival or returning call that may preempt it or need binding.
+		r := running
+		next := now + r.remaining
+		if r.io < len(r.IO) {
+			next = min(next, now+r.IO[r.io].At-(r.BurstDuration-r.remaining))
+		}
+		if kernel.quantum > 0 {
+			next = min(next, quantumEnd)
+		}
+		if len(arrivals) > 0 {
+			next = min(next, arrivals[0].ArrivalTime)
+		}
+		for _, t := range blocked {
+			next = min(next, t.until)
+		}
+		if n := len(gantt); n > 0 && gantt[n-1].PID == r.ProcessID && gantt[n-1].Stop == now {
+			gantt[n-1].Stop = next
+		} else if next > now {
+			gantt = append(gantt, TimeSlice{PID: r.ProcessID, Start: now, Stop: next})
+		}
+		r.remaining -= next - now
+		r.ran += next - now
+		now = next
+
+		switch {
+		case r.remaining == 0:
+			r.exit = now
+			r.process.bound--
+			r.process.Exit = max(r.process.Exit, now)
+			exited++
+			running = nil
+		case r.io < len(r.IO) && r.BurstDuration-r.remaining == r.IO[r.io].At:
+			b := r.IO[r.io]
+			r.io++
+			r.until = now + b.Duration
+			r.blocked += b.Duration
+			waits = append(waits, LockWait{PID: r.ProcessID, Resource: "I/O", Start: now, Stop: r.until})
+			blocked = append(blocked, r)
+			running = nil
+		}
+	}
+
+	rows := make([]ProcessResult, len(all))
+	for i, t := range all {
+		turnaround := t.exit - t.ArrivalTime
+		rows[i] = ProcessResult{
+			Process:    t.Process,
+			Blocked:    t.blocked,
+			Wait:       turnaround - t.ran - t.blocked,
+			Turnaround: turnaround,
+			Exit:       t.exit,
+		}
+	}
+	res := ThreadResult{Result: Summarize(title, gantt, rows), Model: tm.String()}
+	res.Blocked, res.Timescale = waits, kernel.clock
+	for _, tp := range processes {
+		tp.Turnaround = tp.Exit - tp.Arrival
+		res.Processes = append(res.Processes, tp.ThreadProcess)
+		res.AverageProcessTurnaround += float64(tp.Turnaround) / float64(len(processes))
+	}
+
+	return res, nil
+}
+
+// OutputThreadResult outputs a schedule of threads, followed by the turnaround of their processes.
+func OutputThreadResult(w io.Writer, res ThreadResult) {
+	ts := res.Timescale
+	OutputResult(w, res.Result)
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "Processes, %s\n", res.Model)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Process", "Threads", "Kernel threads", "Arrival", "Exit", "Turnaround"})
+	for _, p := range res.Processes {
+		table.Append([]string{
+			p.Process,
+			fmt.Sprint(p.Threads),
+			fmt.Sprint(p.KernelThreads),
+			ts.FormatTime(p.Arrival),
+			ts.FormatTime(p.Exit),
+			ts.FormatTime(p.Turnaround),
+		})
+	}
+	table.Render()
+	_, _ = fmt.Fprintf(w, "Average process turnaround: %s%s\n", ts.FormatMean(res.AverageProcessTurnaround), ts.unitSuffix())
+}
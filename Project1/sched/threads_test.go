@@ -0,0 +1,221 @@
+package sched
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// blocking is a process of two threads, the first of which blocks early, and a process of one.
+var blocking = []Process{
+	{ProcessID: "A1", Parent: "A", BurstDuration: 2, IO: []IOBurst{{At: 1, Duration: 4}}},
+	{ProcessID: "A2", Parent: "A", BurstDuration: 2},
+	{ProcessID: "B1", Parent: "B", BurstDuration: 3},
+}
+
+func threadPolicies(t *testing.T, kernel, user string) (Policy, Policy) {
+	t.Helper()
+	var policies []Policy
+	for _, name := range []string{kernel, user} {
+		alg, err := LookupAlgorithm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		policies = append(policies, alg.Policy(alg.Defaults(), Machine{}))
+	}
+	return policies[0], policies[1]
+}
+
+func Test_simulateThreads(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		model         ThreadModel
+		wantGantt     []TimeSlice
+		wantProcesses []ThreadProcess
+	}{
+		{
+			model: ThreadModel{Name: "1:1"},
+			wantGantt: []TimeSlice{
+				{PID: "A1", Start: 0, Stop: 1},
+				{PID: "A2", Start: 1, Stop: 3},
+				{PID: "B1", Start: 3, Stop: 6},
+				{PID: "A1", Start: 6, Stop: 7},
+			},
+			wantProcesses: []ThreadProcess{
+				{Process: "A", Threads: 2, KernelThreads: 2, Exit: 7, Turnaround: 7},
+				{Process: "B", Threads: 1, KernelThreads: 1, Exit: 6, Turnaround: 6},
+			},
+		},
+		{
+			// A1 blocking holds A's only kernel thread, so A2 can't run until A1 exits.
+			model: ThreadModel{Name: "N:1"},
+			wantGantt: []TimeSlice{
+				{PID: "A1", Start: 0, Stop: 1},
+				{PID: "B1", Start: 1, Stop: 4},
+				{PID: "A1", Start: 5, Stop: 6},
+				{PID: "A2", Start: 6, Stop: 8},
+			},
+			wantProcesses: []ThreadProcess{
+				{Process: "A", Threads: 2, KernelThreads: 1, Exit: 8, Turnaround: 8},
+				{Process: "B", Threads: 1, KernelThreads: 1, Exit: 4, Turnaround: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.model.Name, func(t *testing.T) {
+			t.Parallel()
+			kernel, user := threadPolicies(t, "fcfs", "fcfs")
+			res, err := SimulateThreads(kernel, user, tt.model, blocking, "threads")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantGantt, res.Gantt); diff != "" {
+				t.Errorf(diff)
+			}
+			if diff := cmp.Diff(tt.wantProcesses, res.Processes); diff != "" {
+				t.Errorf(diff)
+			}
+			if diff := cmp.Diff([]LockWait{{PID: "A1", Resource: "I/O", Start: 1, Stop: 5}}, res.Blocked); diff != "" {
+				t.Errorf(diff)
+			}
+			if err := CheckInvariants(blocking, res.Result, false); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// withThreads groups processes into a few processes of threads, and gives some threads
+// blocking calls.
+func withThreads(r *rand.Rand, processes []Process) []Process {
+	owners := 1 + r.IntN(3)
+	for i := range processes {
+		p := &processes[i]
+		p.Parent = fmt.Sprint("P", r.IntN(owners))
+		for at := int64(1) + r.Int64N(3); at < p.BurstDuration && r.IntN(2) == 0; at += 1 + r.Int64N(3) {
+			p.IO = append(p.IO, IOBurst{At: at, Duration: 1 + r.Int64N(5)})
+		}
+	}
+	return processes
+}
+
+func Test_simulateThreads_models(t *testing.T) {
+	t.Parallel()
+	ignoreModel := cmpopts.IgnoreFields(ThreadResult{}, "Model")
+	ignoreKernelThreads := cmpopts.IgnoreFields(ThreadProcess{}, "KernelThreads")
+	for _, names := range [][2]string{{"fcfs", "fcfs"}, {"rr", "fcfs"}, {"sjfp", "sjf"}, {"priority; preemptive", "burst"}} {
+		names := names
+		t.Run(names[0]+"/"+names[1], func(t *testing.T) {
+			t.Parallel()
+			kernel, user := threadPolicies(t, names[0], names[1])
+			r := rand.New(rand.NewPCG(4800, uint64(len(names[0]))))
+			for i := 0; i < 300; i++ {
+				threads := withThreads(r, randomWorkload(r, 1+r.IntN(12)))
+				results := make(map[string]ThreadResult)
+				for _, tm := range []ThreadModel{{Name: "1:1"}, {Name: "N:1"}, {Name: "M:N", KernelThreads: 1}, {Name: "M:N", KernelThreads: 12}} {
+					res, err := SimulateThreads(kernel, user, tm, threads, "threads")
+					if err != nil {
+						t.Fatalf("threads %+v: %v", threads, err)
+					}
+					if err := CheckInvariants(threads, res.Result, false); err != nil {
+						t.Fatalf("threads %+v, %s:\n%v", threads, tm, err)
+					}
+					results[tm.String()] = res
+				}
+				// M:N with a kernel thread per user thread is 1:1, and with one is N:1.
+				if diff := cmp.Diff(results["1:1"], results["M:N (12 kernel threads)"], ignoreModel); diff != "" {
+					t.Fatalf("threads %+v: M:N differs from 1:1:\n%s", threads, diff)
+				}
+				if diff := cmp.Diff(results["N:1"], results["M:N (1 kernel threads)"], ignoreModel, ignoreKernelThreads); diff != "" {
+					t.Fatalf("threads %+v: M:N differs from N:1:\n%s", threads, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_simulateThreads_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		kernel  string
+		model   ThreadModel
+		threads []Process
+	}{
+		{
+			name:    "unknown model",
+			kernel:  "fcfs",
+			model:   ThreadModel{Name: "2:1"},
+			threads: blocking,
+		},
+		{
+			name:    "M:N without kernel threads",
+			kernel:  "fcfs",
+			model:   ThreadModel{Name: "M:N"},
+			threads: blocking,
+		},
+		{
+			name:    "locking protocol",
+			kernel:  "sjfp-inherit",
+			model:   ThreadModel{Name: "1:1"},
+			threads: blocking,
+		},
+		{
+			name:   "blocking call outside the burst",
+			kernel: "fcfs",
+			model:  ThreadModel{Name: "1:1"},
+			threads: []Process{
+				{ProcessID: "A1", BurstDuration: 2, IO: []IOBurst{{At: 2, Duration: 1}}},
+			},
+		},
+		{
+			name:   "blocking calls out of order",
+			kernel: "fcfs",
+			model:  ThreadModel{Name: "1:1"},
+			threads: []Process{
+				{ProcessID: "A1", BurstDuration: 5, IO: []IOBurst{{At: 3, Duration: 1}, {At: 2, Duration: 1}}},
+			},
+		},
+		{
+			name:   "locks",
+			kernel: "fcfs",
+			model:  ThreadModel{Name: "1:1"},
+			threads: []Process{
+				{ProcessID: "A1", BurstDuration: 5, Locks: []Lock{{"R1", 0, 4}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			kernel, user := threadPolicies(t, tt.kernel, "fcfs")
+			if _, err := SimulateThreads(kernel, user, tt.model, tt.threads, "threads"); !errors.Is(err, ErrInvalidArgs) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidArgs)
+			}
+		})
+	}
+}
+
+func Test_parseIO(t *testing.T) {
+	t.Parallel()
+	got, err := ParseIO(" 0.5:2; 1:0.5 ", Timescale{Decimals: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IOBurst{{5, 20}, {10, 5}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	if got := FormatIO(got, Timescale{Decimals: 1}); got != "0.5:2.0;1.0:0.5" {
+		t.Errorf("FormatIO() = %q", got)
+	}
+	if _, err := ParseIO("2", Timescale{}); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidArgs)
+	}
+}
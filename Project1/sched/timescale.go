@@ -0,0 +1,92 @@
+package sched
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Timescale relates the simulation's ticks to the decimal times that workloads and schedules
+// are written in: a unit of time, divided into 10^Decimals ticks. The zero Timescale writes
+// times as whole ticks.
+type Timescale struct {
+	Decimals int    `json:"decimals,omitempty"` // digits after the decimal point
+	Unit     string `json:"timeUnit,omitempty"` // name of the unit, e.g. ms, only shown in output
+}
+
+// maxDecimals keeps a tick count of a few years of seconds within an int64, even at ns.
+const maxDecimals = 9
+
+func (ts Timescale) Validate() error {
+	if ts.Decimals < 0 || ts.Decimals > maxDecimals {
+		return fmt.Errorf("%w: times may have 0 to %d decimal places, not %d", ErrInvalidArgs, maxDecimals, ts.Decimals)
+	}
+	return nil
+}
+
+// ticks returns the number of ticks in a unit of time.
+func (ts Timescale) ticks() int64 {
+	n := int64(1)
+	for i := 0; i < ts.Decimals; i++ {
+		n *= 10
+	}
+	return n
+}
+
+// ParseTime parses a decimal time into ticks, exactly: a time more precise than a tick is
+// an error, rather than being rounded.
+func (ts Timescale) ParseTime(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	sign := ""
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign, s = "-", rest
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > ts.Decimals || strings.ContainsAny(whole+frac, "+-") || whole+frac == "" {
+		return 0, fmt.Errorf("%w: %q isn't a time with at most %d decimal places", ErrInvalidArgs, sign+s, ts.Decimals)
+	}
+	t, err := strconv.ParseInt(sign+whole+frac+strings.Repeat("0", ts.Decimals-len(frac)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q isn't a time with at most %d decimal places", ErrInvalidArgs, sign+s, ts.Decimals)
+	}
+	return t, nil
+}
+
+// FormatTime writes ticks as a decimal time, with every decimal place.
+func (ts Timescale) FormatTime(t int64) string {
+	if ts.Decimals == 0 {
+		return strconv.FormatInt(t, 10)
+	}
+	sign := ""
+	if t < 0 {
+		sign, t = "-", -t
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, t/ts.ticks(), ts.Decimals, t%ts.ticks())
+}
+
+// FormatMean writes an average of ticks as a decimal time, with two more decimal places than
+// a time.
+func (ts Timescale) FormatMean(v float64) string {
+	return strconv.FormatFloat(v/float64(ts.ticks()), 'f', ts.Decimals+2, 64)
+}
+
+// FormatChange writes a change in an average of ticks like FormatMean, always signed.
+func (ts Timescale) FormatChange(v float64) string {
+	if v < 0 {
+		return ts.FormatMean(v)
+	}
+	return "+" + ts.FormatMean(v)
+}
+
+// FormatRate writes a rate per tick as a rate per unit of time.
+func (ts Timescale) FormatRate(v float64) string {
+	return strconv.FormatFloat(v*float64(ts.ticks()), 'f', 2, 64)
+}
+
+// unitSuffix returns the unit to write after a time, if it has a name.
+func (ts Timescale) unitSuffix() string {
+	if ts.Unit == "" {
+		return ""
+	}
+	return " " + ts.Unit
+}
@@ -0,0 +1,103 @@
+package sched
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTimescale_ParseTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		ts      Timescale
+		s       string
+		want    int64
+		wantErr error
+	}{
+		{name: "ticks", s: "42", want: 42},
+		{name: "decimal", ts: Timescale{Decimals: 3}, s: "1.5", want: 1500},
+		{name: "every place", ts: Timescale{Decimals: 3}, s: " 0.001 ", want: 1},
+		{name: "no whole part", ts: Timescale{Decimals: 2}, s: ".25", want: 25},
+		{name: "negative", ts: Timescale{Decimals: 1}, s: "-1.5", want: -15},
+		{name: "more precise than a tick", ts: Timescale{Decimals: 1}, s: "1.25", wantErr: ErrInvalidArgs},
+		{name: "fraction of whole ticks", s: "1.5", wantErr: ErrInvalidArgs},
+		{name: "empty", ts: Timescale{Decimals: 1}, s: ".", wantErr: ErrInvalidArgs},
+		{name: "double sign", s: "--1", wantErr: ErrInvalidArgs},
+		{name: "signed fraction", ts: Timescale{Decimals: 1}, s: "1.-5", wantErr: ErrInvalidArgs},
+		{name: "exponent", ts: Timescale{Decimals: 1}, s: "1e3", wantErr: ErrInvalidArgs},
+		{name: "overflow", ts: Timescale{Decimals: 9}, s: "10000000000", wantErr: ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.ts.ParseTime(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTime() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimescale_Format(t *testing.T) {
+	t.Parallel()
+	ts := Timescale{Decimals: 3}
+	got := []string{
+		ts.FormatTime(1500),
+		ts.FormatTime(7),
+		ts.FormatTime(-1500),
+		ts.FormatMean(1234.5),
+		ts.FormatChange(-500),
+		ts.FormatChange(500),
+		ts.FormatRate(0.002),
+		Timescale{}.FormatTime(12),
+		Timescale{}.FormatMean(2.5),
+	}
+	want := []string{"1.500", "0.007", "-1.500", "1.23450", "-0.50000", "+0.50000", "2.00", "12", "2.50"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// Test_timescale_schedules checks that writing the same whole times with more decimal places
+// only makes the ticks finer, leaving every algorithm's schedule the same. Scaling the CPU's
+// frequency rounds work to whole ticks, so it only gets more accurate.
+func Test_timescale_schedules(t *testing.T) {
+	t.Parallel()
+	ts := Timescale{Decimals: 2}
+	scaled := make([]Process, len(exampleProcesses))
+	for i, p := range exampleProcesses {
+		scaled[i] = p
+		scaled[i].ArrivalTime *= ts.ticks()
+		scaled[i].BurstDuration *= ts.ticks()
+	}
+	for _, alg := range Algorithms() {
+		alg := alg
+		if alg.Name == "energy" {
+			continue
+		}
+		t.Run(alg.Name, func(t *testing.T) {
+			t.Parallel()
+			want, err := Run(alg, alg.Defaults(), Machine{}, exampleProcesses)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Run(alg, alg.Defaults(), Machine{Clock: ts}, scaled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range want.Gantt {
+				want.Gantt[i].Start *= ts.ticks()
+				want.Gantt[i].Stop *= ts.ticks()
+			}
+			if diff := cmp.Diff(want.Gantt, got.Gantt); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -1,86 +0,0 @@
-package main
-
-import (
-	"fmt"
-	"io"
-)
-
-type (
-	Process struct {
-		ProcessID     string
-		ArrivalTime   int64
-		BurstDuration int64
-		Priority      int64
-	}
-	TimeSlice struct {
-		PID   string
-		Start int64
-		Stop  int64
-	}
-)
-
-//region Schedulers
-
-// FCFSSchedule outputs a schedule of processes in a GANTT chart and a table of timing given:
-// • an output writer
-// • a title for the chart
-// • a slice of processes
-func FCFSSchedule(w io.Writer, title string, processes []Process) {
-	var (
-		serviceTime     int64
-		totalWait       float64
-		totalTurnaround float64
-		lastCompletion  float64
-		waitingTime     int64
-		schedule        = make([][]string, len(processes))
-		gantt           = make([]TimeSlice, 0)
-	)
-	for i := range processes {
-		if processes[i].ArrivalTime > 0 {
-			waitingTime = serviceTime - processes[i].ArrivalTime
-		}
-		totalWait += float64(waitingTime)
-
-		start := waitingTime + processes[i].ArrivalTime
-
-		turnaround := processes[i].BurstDuration + waitingTime
-		totalTurnaround += float64(turnaround)
-
-		completion := processes[i].BurstDuration + processes[i].ArrivalTime + waitingTime
-		lastCompletion = float64(completion)
-
-		schedule[i] = []string{
-			fmt.Sprint(processes[i].ProcessID),
-			fmt.Sprint(processes[i].Priority),
-			fmt.Sprint(processes[i].BurstDuration),
-			fmt.Sprint(processes[i].ArrivalTime),
-			fmt.Sprint(waitingTime),
-			fmt.Sprint(turnaround),
-			fmt.Sprint(completion),
-		}
-		serviceTime += processes[i].BurstDuration
-
-		gantt = append(gantt, TimeSlice{
-			PID:   processes[i].ProcessID,
-			Start: start,
-			Stop:  serviceTime,
-		})
-	}
-
-	count := float64(len(processes))
-	aveWait := totalWait / count
-	aveTurnaround := totalTurnaround / count
-	aveThroughput := count / lastCompletion
-
-	outputTitle(w, title)
-	outputGantt(w, gantt)
-	outputSchedule(w, schedule, aveWait, aveTurnaround, aveThroughput)
-}
-
-func SJFSchedule(w io.Writer, title string, processes []Process) {}
-
-func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {}
-
-func RRSchedule(w io.Writer, title string, processes []Process) {}
-
-//endregion
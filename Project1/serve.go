@@ -0,0 +1,209 @@
+package main
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jh125486/CSCE4600/Project1/sched"
+)
+
+//go:embed static
+var static embed.FS
+
+const (
+	// maxWorkload is the largest workload accepted by the run API, in bytes.
+	maxWorkload = 1 << 20
+	// maxTotalBurst is the most CPU time, in ticks, the run API simulates. A time-sliced
+	// schedule takes a step every quantum, so this bounds how long a request can take.
+	maxTotalBurst = 10_000_000
+)
+
+type (
+	// paramJSON describes an algorithm's param to API clients.
+	paramJSON struct {
+		Name    string `json:"name"`
+		Usage   string `json:"usage"`
+		Default int64  `json:"default"`
+		Min     int64  `json:"min"`
+		Max     int64  `json:"max,omitempty"`
+		Time    bool   `json:"time,omitempty"` // in units of the timescale
+	}
+
+	// algorithmJSON describes a registered algorithm to API clients.
+	algorithmJSON struct {
+		Name   string      `json:"name"`
+		Title  string      `json:"title"`
+		Usage  string      `json:"usage"`
+		Params []paramJSON `json:"params"`
+	}
+)
+
+func serveCmd(flagSet *flag.FlagSet, args []string, _ *os.File, w io.Writer) error {
+	addr := flagSet.String("addr", "localhost:8080", "Address to listen on")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 0 {
+		return fmt.Errorf("%w: serve takes no arguments", sched.ErrInvalidArgs)
+	}
+	_, _ = fmt.Fprintf(w, "Serving on http://%s\n", *addr)
+
+	return newServer(*addr).ListenAndServe()
+}
+
+// newServer serves newHandler on addr, with timeouts so that slow clients can't hold
+// connections open.
+func newServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           newHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+}
+
+// newHandler serves the web UI at /, and the API under /api/:
+// • GET /api/algorithms lists the registered algorithms and their params
+// • POST /api/run?algorithm=<name>[&<param>=<value>...] runs a workload, posted as CSV or
+// as a JSON array of processes, returning the schedule as JSON. The machine is described by
+// the memory, swap and power query params, like the flags of the same names, and the times
+// of a CSV workload by the decimals and unit query params, like -decimals and -time-unit.
+// Workloads whose bursts add up to more than maxTotalBurst ticks are rejected.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	root, _ := fs.Sub(static, "static")
+	mux.Handle("GET /{$}", http.FileServerFS(root))
+	mux.HandleFunc("GET /api/algorithms", listAlgorithms)
+	mux.HandleFunc("POST /api/run", runAlgorithm)
+
+	return mux
+}
+
+func listAlgorithms(w http.ResponseWriter, _ *http.Request) {
+	algs := sched.Algorithms()
+	res := make([]algorithmJSON, len(algs))
+	for i, alg := range algs {
+		res[i] = algorithmJSON{Name: alg.Name, Title: alg.Title, Usage: alg.Usage, Params: []paramJSON{}}
+		for _, p := range alg.Params {
+			res[i].Params = append(res[i].Params, paramJSON(p))
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func runAlgorithm(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	alg, err := sched.LookupAlgorithm(query.Get("algorithm"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	params := alg.Defaults()
+	for _, p := range alg.Params {
+		if v := query.Get(p.Name); v != "" {
+			if params[p.Name], err = strconv.ParseInt(v, 10, 64); err != nil {
+				writeError(w, fmt.Errorf("%w: %s %q isn't an integer", sched.ErrInvalidArgs, p.Name, v))
+				return
+			}
+		}
+	}
+	var m sched.Machine
+	if v := query.Get("memory"); v != "" {
+		if m.Memory, err = strconv.ParseInt(v, 10, 64); err != nil {
+			writeError(w, fmt.Errorf("%w: memory %q isn't an integer", sched.ErrInvalidArgs, v))
+			return
+		}
+	}
+	m.Swap = query.Get("swap") == "true"
+	if v := query.Get("decimals"); v != "" {
+		if m.Clock.Decimals, err = strconv.Atoi(v); err != nil {
+			writeError(w, fmt.Errorf("%w: decimals %q isn't an integer", sched.ErrInvalidArgs, v))
+			return
+		}
+	}
+	m.Clock.Unit = query.Get("unit")
+	if err := m.Clock.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+	if v := query.Get("power"); v != "" {
+		if m.Power, err = sched.ParsePowerModel(v); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	processes, err := readWorkload(w, r, m.Clock)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var total int64
+	for _, p := range processes {
+		if p.BurstDuration > maxTotalBurst-total {
+			writeError(w, fmt.Errorf("%w: the bursts add up to more than %d ticks, the most the API simulates", sched.ErrInvalidArgs, maxTotalBurst))
+			return
+		}
+		total += max(p.BurstDuration, 0)
+	}
+	res, err := sched.Run(alg, params, m, processes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// readWorkload reads the processes posted as JSON, with times in ticks, or else as CSV, with
+// decimal times of ts.
+func readWorkload(w http.ResponseWriter, r *http.Request, ts sched.Timescale) ([]sched.Process, error) {
+	body := http.MaxBytesReader(w, r.Body, maxWorkload)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return sched.LoadProcessesScaled(body, ts)
+	}
+	var processes []sched.Process
+	if err := json.NewDecoder(body).Decode(&processes); err != nil {
+		return nil, fmt.Errorf("%w: reading JSON: %w", sched.ErrInvalidArgs, err)
+	}
+
+	return processes, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
+
+// writeError reports err as JSON, blaming the client for invalid input.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var (
+		tooLarge *http.MaxBytesError
+		badCSV   *csv.ParseError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, sched.ErrInvalidArgs), errors.As(err, &badCSV):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
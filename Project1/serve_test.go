@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jh125486/CSCE4600/Project1/sched"
+)
+
+func Test_newHandler(t *testing.T) {
+	t.Parallel()
+	const workload = `ProcessID,Burst Duration,Arrival Time,Priority
+A,2,0,1
+B,1,0,2`
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		want        []string
+	}{
+		{
+			name:       "web UI",
+			method:     http.MethodGet,
+			target:     "/",
+			wantStatus: http.StatusOK,
+			want:       []string{"<title>Process Scheduler</title>"},
+		},
+		{
+			name:       "algorithms",
+			method:     http.MethodGet,
+			target:     "/api/algorithms",
+			wantStatus: http.StatusOK,
+			want:       []string{`{"name":"rr","title":"Round-robin","usage":"Round-robin scheduling","params":[{"name":"quantum"`},
+		},
+		{
+			name:       "run CSV",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=rr&quantum=2",
+			body:       workload,
+			wantStatus: http.StatusOK,
+			want:       []string{`"gantt":[{"pid":"A","start":0,"stop":2},{"pid":"B","start":2,"stop":3}]`},
+		},
+		{
+			name:       "run CSV with decimal times",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=fcfs&decimals=1&unit=ms",
+			body:       "ProcessID,Burst Duration,Arrival Time\nA,0.5,0\nB,1.5,0.2",
+			wantStatus: http.StatusOK,
+			want:       []string{`"gantt":[{"pid":"A","start":0,"stop":5},{"pid":"B","start":5,"stop":20}]`, `"decimals":1,"timeUnit":"ms"`},
+		},
+		{
+			name:       "too many decimals",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=fcfs&decimals=10",
+			body:       workload,
+			wantStatus: http.StatusBadRequest,
+			want:       []string{`"error":"invalid args: times may have 0 to 9 decimal places, not 10"`},
+		},
+		{
+			name:        "run JSON",
+			method:      http.MethodPost,
+			target:      "/api/run?algorithm=sjf",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"id":"A","burst":2},{"id":"B","burst":1}]`,
+			wantStatus:  http.StatusOK,
+			want:        []string{`"gantt":[{"pid":"B","start":0,"stop":1},{"pid":"A","start":1,"stop":3}]`},
+		},
+		{
+			name:       "unknown algorithm",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=lottery",
+			body:       workload,
+			wantStatus: http.StatusBadRequest,
+			want:       []string{`"error":"invalid args: unknown algorithm \"lottery\"`},
+		},
+		{
+			name:       "params are validated",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=rr&quantum=0",
+			body:       workload,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad CSV",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=fcfs",
+			body:       "ProcessID,Burst Duration,Arrival Time\nA,two,0",
+			wantStatus: http.StatusBadRequest,
+			want:       []string{`line 2, Burst Duration: \"two\" isn't an integer`},
+		},
+		{
+			name:       "too large",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=fcfs",
+			body:       workload + strings.Repeat("\nA,1,0,1", maxWorkload/8),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "too much CPU time to simulate",
+			method:     http.MethodPost,
+			target:     "/api/run?algorithm=rr",
+			body:       "ProcessID,Burst Duration,Arrival Time\nA,1000000000000,0",
+			wantStatus: http.StatusBadRequest,
+			want:       []string{`"error":"invalid args: the bursts add up to more than 10000000 ticks`},
+		},
+		{
+			name:        "too much CPU time in total",
+			method:      http.MethodPost,
+			target:      "/api/run?algorithm=rr",
+			contentType: "application/json",
+			body:        `[{"id":"A","burst":6000000},{"id":"B","burst":6000000}]`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:       "run must be posted",
+			method:     http.MethodGet,
+			target:     "/api/run?algorithm=fcfs",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	handler := newHandler()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(rec.Body.String(), s) {
+					t.Errorf("body missing %s:\n%s", s, rec.Body)
+				}
+			}
+		})
+	}
+}
+
+func Test_newHandler_result(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodPost, "/api/run?algorithm=fcfs", strings.NewReader("ProcessID,Burst Duration,Arrival Time\nA,2,1"))
+	rec := httptest.NewRecorder()
+	newHandler().ServeHTTP(rec, req)
+	var res sched.Result
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Title != "First-come, first-serve" || len(res.Rows) != 1 || res.Rows[0].Exit != 3 {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func Test_newServer(t *testing.T) {
+	t.Parallel()
+	s := newServer("localhost:0")
+	if s.ReadHeaderTimeout <= 0 || s.ReadTimeout <= 0 || s.WriteTimeout <= 0 || s.IdleTimeout <= 0 {
+		t.Errorf("server has no timeouts: %+v", s)
+	}
+}
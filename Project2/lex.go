@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnterminatedQuote is returned for input that ends inside single or double quotes.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+	// ErrUnfinishedEscape is returned for input that ends with a backslash, escaping nothing.
+	ErrUnfinishedEscape = errors.New("unfinished escape")
+)
+
+// splitArgs splits a command line into words the way sh does, without expansions:
+//   - spaces, tabs, carriage returns and newlines separate words, however many there are
+//   - single quotes keep everything up to the next single quote as is
+//   - double quotes keep everything up to the next unescaped double quote, where a backslash
+//     only escapes $, `, ", \ and a newline
+//   - a backslash outside quotes keeps the next character as is
+//   - a backslash before a newline joins the lines, outside single quotes
+//
+// Quotes can make part of a word, and "" on its own is an empty word.
+// Input that ends inside quotes or after a backslash returns ErrUnterminatedQuote or
+// ErrUnfinishedEscape, which more lines of input can complete (see incomplete).
+func splitArgs(input string) ([]string, error) {
+	var (
+		args  []string
+		word  strings.Builder
+		inArg bool // a word has started, even if it's still empty
+		runes = []rune(input)
+	)
+	// position is the line and column of runes[i], counting from 1.
+	position := func(i int) (int, int) {
+		l, c := 1, 0
+		for _, r := range runes[:i+1] {
+			c++
+			if r == '\n' {
+				l, c = l+1, 0
+			}
+		}
+		return l, c
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inArg {
+				args = append(args, word.String())
+				word.Reset()
+				inArg = false
+			}
+		case r == '\\':
+			if i+1 == len(runes) || runes[i+1] == '\n' && i+2 == len(runes) {
+				return nil, fmt.Errorf("%w: input ends with a backslash, expected more on the next line", ErrUnfinishedEscape)
+			}
+			i++
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inArg = true
+			}
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				l, c := position(i)
+				return nil, fmt.Errorf("%w: ' opened at line %d, column %d is never closed", ErrUnterminatedQuote, l, c)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inArg = true
+			i = end
+		case r == '"':
+			start := i
+			inArg = true
+			for i++; ; i++ {
+				if i == len(runes) {
+					l, c := position(start)
+					return nil, fmt.Errorf("%w: \" opened at line %d, column %d is never closed", ErrUnterminatedQuote, l, c)
+				}
+				if runes[i] == '"' {
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+		default:
+			word.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, word.String())
+	}
+
+	return args, nil
+}
+
+// incomplete reports whether input continues on the next line: it ends inside quotes or after
+// a backslash.
+func incomplete(input string) bool {
+	_, err := splitArgs(input)
+	return errors.Is(err, ErrUnterminatedQuote) || errors.Is(err, ErrUnfinishedEscape)
+}
+
+// indexRune returns the index of the first r in runes from start on, or -1.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
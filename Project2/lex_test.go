@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitArgs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		input          string
+		want           []string
+		wantErr        error
+		wantIncomplete bool
+	}{
+		{
+			name:  "empty",
+			input: " \t\n",
+		},
+		{
+			name:  "spaces and tabs",
+			input: "ls  -l\t-a \n",
+			want:  []string{"ls", "-l", "-a"},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "cd dir\r\n",
+			want:  []string{"cd", "dir"},
+		},
+		{
+			name:  "double quotes",
+			input: `echo "hello  world" "a \"b\" \\ \$HOME \x"`,
+			want:  []string{"echo", "hello  world", `a "b" \ $HOME \x`},
+		},
+		{
+			name:  "single quotes",
+			input: `echo 'it''s \n "here"'`,
+			want:  []string{"echo", `its \n "here"`},
+		},
+		{
+			name:  "empty quotes",
+			input: `echo "" ''`,
+			want:  []string{"echo", "", ""},
+		},
+		{
+			name:  "quotes inside a word",
+			input: `echo a"b c"'d e'f`,
+			want:  []string{"echo", "ab cd ef"},
+		},
+		{
+			name:  "backslash escapes",
+			input: `echo hello\ world \"\' \\`,
+			want:  []string{"echo", "hello world", `"'`, `\`},
+		},
+		{
+			name:  "line continuation",
+			input: "echo a\\\nb \\\n c \"d\\\ne\"\n",
+			want:  []string{"echo", "ab", "c", "de"},
+		},
+		{
+			name:  "quotes across lines",
+			input: "echo 'a\nb' \"c\nd\"\n",
+			want:  []string{"echo", "a\nb", "c\nd"},
+		},
+		{
+			name:           "unterminated single quote",
+			input:          "echo 'abc\n",
+			wantErr:        ErrUnterminatedQuote,
+			wantIncomplete: true,
+		},
+		{
+			name:           "unterminated double quote",
+			input:          "echo a\n\"b\\\"",
+			wantErr:        ErrUnterminatedQuote,
+			wantIncomplete: true,
+		},
+		{
+			name:           "trailing backslash",
+			input:          "echo a \\\n",
+			wantErr:        ErrUnfinishedEscape,
+			wantIncomplete: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := splitArgs(tt.input)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantIncomplete, incomplete(tt.input))
+		})
+	}
+}
+
+func Test_splitArgs_position(t *testing.T) {
+	t.Parallel()
+	_, err := splitArgs("echo a\n  \"b\n")
+	require.EqualError(t, err, `unterminated quote: " opened at line 2, column 3 is never closed`)
+}
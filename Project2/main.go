@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
-	"strings"
 
 	"github.com/jh125486/CSCE4600/Project2/builtins"
 )
@@ -37,6 +36,18 @@ func runLoop(r io.Reader, w, errW io.Writer, exit chan struct{}) {
 				_, _ = fmt.Fprintln(errW, err)
 				continue
 			}
+			// Keep reading while a quote or a trailing backslash carries the input over to the next line.
+			for incomplete(input) {
+				if _, err = fmt.Fprint(w, "> "); err != nil {
+					break
+				}
+				var line string
+				line, err = readLoop.ReadString('\n')
+				input += line
+				if err != nil {
+					break // handleInput reports what was left unfinished.
+				}
+			}
 			if err = handleInput(w, input, exit); err != nil {
 				_, _ = fmt.Fprintln(errW, err)
 			}
@@ -64,11 +75,14 @@ func printPrompt(w io.Writer) error {
 }
 
 func handleInput(w io.Writer, input string, exit chan<- struct{}) error {
-	// Remove trailing spaces.
-	input = strings.TrimSpace(input)
-
-	// Split the input separate the command name and the command arguments.
-	args := strings.Split(input, " ")
+	// Split the input into words, separating the command name and the command arguments.
+	args, err := splitArgs(input)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
 	name, args := args[0], args[1:]
 
 	// Check for built-in commands.
@@ -5,11 +5,27 @@ import (
 	"github.com/stretchr/testify/require"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 	"testing/iotest"
-	"time"
 )
 
+// exitOnError reads from r, and asks the loop to exit the first time a read fails, as a
+// loop whose input has run out would otherwise keep prompting forever.
+type exitOnError struct {
+	r    io.Reader
+	exit chan<- struct{}
+	once sync.Once
+}
+
+func (e *exitOnError) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil {
+		e.once.Do(func() { e.exit <- struct{}{} })
+	}
+	return n, err
+}
+
 func Test_runLoop(t *testing.T) {
 	t.Parallel()
 	exitCmd := strings.NewReader("exit\n")
@@ -35,6 +51,14 @@ func Test_runLoop(t *testing.T) {
 			},
 			wantErrW: "EOF",
 		},
+		{
+			name: "unterminated quote continues until the input ends",
+			args: args{
+				r: strings.NewReader("echo 'hello\nworld\n"),
+			},
+			wantW:    "> ",
+			wantErrW: "unterminated quote: ' opened at line 1, column 6 is never closed",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -44,12 +68,17 @@ func Test_runLoop(t *testing.T) {
 			errW := &bytes.Buffer{}
 
 			exit := make(chan struct{}, 2)
-			// run the loop for 10ms
-			go runLoop(tt.args.r, w, errW, exit)
-			time.Sleep(10 * time.Millisecond)
-			exit <- struct{}{}
+			// run the loop until its input runs out, then wait for it to return.
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runLoop(&exitOnError{r: tt.args.r, exit: exit}, w, errW, exit)
+			}()
+			wg.Wait()
 
 			require.NotEmpty(t, w.String())
+			require.Contains(t, w.String(), tt.wantW)
 			if tt.wantErrW != "" {
 				require.Contains(t, errW.String(), tt.wantErrW)
 			} else {